@@ -69,3 +69,53 @@ func TestGetPokemon(t *testing.T) {
 	}
 
 }
+
+func TestGetPokemonDetail(t *testing.T) {
+
+	pag, err := paginationaggregator.NewPaginationAggregator(&paginationaggregator.PaginationAggregatorConfig{
+		URL:        "https://pokeapi.co/api/v2/pokemon?limit=10&offset={offset}",
+		Limit:      10,
+		Boundary:   2,
+		Client:     &http.Client{},
+		Concurrent: 2,
+		ItemsPath:  "results",
+		Enrichment: &paginationaggregator.Enrichment{
+			URLField: "url",
+			Field:    "detail",
+		},
+		ConcurrentBatch: func(batchResult []paginationaggregator.HttpInteraction) error {
+
+			for _, val := range batchResult {
+
+				for _, item := range val.Response.Items {
+
+					fields, ok := item.(map[string]interface{})
+
+					if !ok {
+						t.Fatalf("Item is not an object, actual %v", item)
+					}
+
+					detail, ok := fields["detail"].(map[string]interface{})
+
+					if !ok {
+						t.Fatalf("Detail not stored into item, actual %v", fields)
+					}
+
+					t.Log(detail["height"])
+				}
+			}
+
+			return nil
+		},
+	})
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	_, err = pag.Get()
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+}
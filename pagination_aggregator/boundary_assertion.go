@@ -1,49 +1,37 @@
 package paginationaggregator
 
-import (
-	"context"
-	"encoding/json"
-	"fmt"
-	"net/http"
-	"time"
-)
+import "net/http"
 
+// BoundaryAssertion discover boundary from first page before pagination when Boundary is not configured
 type BoundaryAssertion struct {
+	BasePlugin
 }
 
-func (obj *BoundaryAssertion) accept(pag *PaginationAggregator) error {
+func (obj *BoundaryAssertion) BeforeRun(state *State) error {
 
-	var client = &http.Client{}
-	var url = fmt.Sprintf(pag.url, 1)
-
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(pag.timeout)*time.Second)
-	defer cancel()
+	// boundary is already discovered by previous plugin
+	if state.Boundary() != 0 {
+		return nil
+	}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	interaction := state.Fetch(1)
 
-	if err != nil {
-		return err
+	// range of first page is not satisfiable on empty collection, nothing is paginated
+	if state.pag.rangePages != nil && interaction.Response.Status == http.StatusRequestedRangeNotSatisfiable {
+		return nil
 	}
 
-	for key, value := range pag.headers {
-		req.Header.Set(key, value)
+	if interaction.Response.Error != nil {
+		return interaction.Response.Error
 	}
 
-	resp, err := client.Do(req)
+	boundary, err := state.ReadBoundary(interaction.Response.Header, []byte(interaction.Response.Data))
 
 	if err != nil {
 		return err
 	}
 
-	defer resp.Body.Close()
-
-	if err := json.NewDecoder(resp.Body).Decode(&pag.jsonPages); err != nil {
-		return err
-	}
-
-	pag.boundary = pag.jsonPages.GetBoundary()
-
-	return nil
+	return state.SetBoundary(boundary)
 }
 
 func newBoundaryAssertion() *BoundaryAssertion {
@@ -0,0 +1,109 @@
+package paginationaggregator
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// CursorStrategy drive sequential pagination where next request depends on previous response
+type CursorStrategy interface {
+
+	// Apply cursor into page request, cursor is empty on first request
+	Apply(req *http.Request, cursor string) error
+
+	// Next extract cursor of next request from fetched page, return false when there is no more page.
+	// Page failures which only detected from response body (e.g. GraphQL errors) can be set on interaction
+	Next(interaction *HttpInteraction) (string, bool)
+}
+
+type validator interface {
+	validate() error
+}
+
+// fetch pages one by one following cursor, every Concurrent pages are grouped into one batch
+func (obj *PaginationAggregator) getByCursor() ([]HttpInteraction, error) {
+	return obj.getByCursorFrom(obj.start, "", nil)
+}
+
+// fetch pages from given pointer and cursor, pending pages are collected within first batch
+func (obj *PaginationAggregator) getByCursorFrom(start int, cursor string, tmpBatch []HttpInteraction) ([]HttpInteraction, error) {
+
+	for pointer := start; obj.boundary == 0 || pointer <= obj.boundary; pointer++ {
+
+		interaction := obj.fetchCursor(pointer, cursor, nil)
+
+		if obj.recoverExpiredToken(interaction) {
+			cursor = ""
+			pointer--
+			continue
+		}
+
+		obj.trackExpiryItems(&interaction)
+
+		next, ok := cursor, false
+
+		if interaction.Response.Error == nil {
+			next, ok = obj.cursor.Next(&interaction)
+		}
+
+		// latest cursor resource is closed even when its page is never collected
+		if obj.session != nil && ok && next != "" {
+			obj.trackCursor(next)
+		}
+
+		tmpBatch = append(tmpBatch, interaction)
+
+		last := !ok || interaction.Response.Error != nil || next == ""
+
+		if len(tmpBatch) == obj.concurrent || last || pointer == obj.boundary {
+
+			if err := obj.collect(tmpBatch); err != nil {
+				return obj.finish(err)
+			}
+
+			tmpBatch = nil
+		}
+
+		if last {
+			break
+		}
+
+		cursor = next
+
+		if obj.ctx != nil && obj.ctx.Err() != nil {
+			return nil, obj.ctx.Err()
+		}
+	}
+
+	return obj.result, nil
+}
+
+func (obj *PaginationAggregator) fetchCursor(page int, cursor string, params map[string]string) HttpInteraction {
+
+	requestCtx, cancel := context.WithTimeout(context.Background(), time.Duration(obj.timeout)*time.Second)
+	defer cancel()
+
+	if obj.transport != nil {
+		interaction := obj.call(requestCtx, page, cursor, params)
+		interaction.Request.Cursor = cursor
+		return interaction
+	}
+
+	values := obj.templateValues(page, cursor)
+
+	for key, value := range params {
+		values[key] = value
+	}
+
+	req, err := obj.newRequestWithValues(requestCtx, page, values)
+
+	if err == nil {
+		err = obj.cursor.Apply(req, cursor)
+	}
+
+	interaction := obj.roundTrip(req, err, page)
+	interaction.Request.Cursor = cursor
+
+	return interaction
+}
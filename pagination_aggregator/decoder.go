@@ -0,0 +1,333 @@
+package paginationaggregator
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"mime"
+	"strings"
+	"sync"
+)
+
+const (
+	CONTENT_TYPE_JSON    = "application/json"
+	CONTENT_TYPE_XML     = "application/xml"
+	CONTENT_TYPE_CSV     = "text/csv"
+	CONTENT_TYPE_NDJSON  = "application/x-ndjson"
+	CONTENT_TYPE_MSGPACK = "application/msgpack"
+)
+
+// Decoder decode response data into generic tree of map[string]interface{}, []interface{} and scalar values,
+// the tree is used for boundary discovery, cursor extraction and item extraction
+type Decoder interface {
+	Decode(data []byte) (interface{}, error)
+}
+
+type DecoderFunc func(data []byte) (interface{}, error)
+
+func (fn DecoderFunc) Decode(data []byte) (interface{}, error) {
+	return fn(data)
+}
+
+type jsonDecoder struct{}
+
+type xmlDecoder struct{}
+
+type csvDecoder struct{}
+
+type ndjsonDecoder struct{}
+
+type msgPackDecoder struct{}
+
+var (
+	JSONDecoder    Decoder = jsonDecoder{}
+	XMLDecoder     Decoder = xmlDecoder{}
+	CSVDecoder     Decoder = csvDecoder{}
+	NDJSONDecoder  Decoder = ndjsonDecoder{}
+	MsgPackDecoder Decoder = msgPackDecoder{}
+)
+
+var decoderRegistry = struct {
+	sync.RWMutex
+	decoders map[string]Decoder
+}{
+	decoders: map[string]Decoder{
+		CONTENT_TYPE_JSON:         JSONDecoder,
+		"text/json":               JSONDecoder,
+		CONTENT_TYPE_XML:          XMLDecoder,
+		"text/xml":                XMLDecoder,
+		CONTENT_TYPE_CSV:          CSVDecoder,
+		CONTENT_TYPE_NDJSON:       NDJSONDecoder,
+		"application/jsonl":       NDJSONDecoder,
+		"application/x-jsonlines": NDJSONDecoder,
+		CONTENT_TYPE_MSGPACK:      MsgPackDecoder,
+		"application/x-msgpack":   MsgPackDecoder,
+		"application/vnd.msgpack": MsgPackDecoder,
+	},
+}
+
+// Register decoder of given Content-Type (e.g. application/yaml)
+func RegisterDecoder(contentType string, decoder Decoder) {
+
+	decoderRegistry.Lock()
+	defer decoderRegistry.Unlock()
+
+	decoderRegistry.decoders[strings.ToLower(contentType)] = decoder
+}
+
+// decoder of Content-Type header, structured syntax suffix (e.g. +json, +xml) and unknown types fall back to json
+func decoderFor(contentType string) Decoder {
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+
+	if err != nil {
+		return JSONDecoder
+	}
+
+	decoderRegistry.RLock()
+	defer decoderRegistry.RUnlock()
+
+	if decoder, ok := decoderRegistry.decoders[mediaType]; ok {
+		return decoder
+	}
+
+	if strings.HasSuffix(mediaType, "+xml") {
+		return XMLDecoder
+	}
+
+	return JSONDecoder
+}
+
+func (obj jsonDecoder) Decode(data []byte) (interface{}, error) {
+	return decodeJSON(data)
+}
+
+func (obj xmlDecoder) Decode(data []byte) (interface{}, error) {
+	return decodeXML(data)
+}
+
+func (obj csvDecoder) Decode(data []byte) (interface{}, error) {
+	return decodeCSV(data)
+}
+
+func (obj ndjsonDecoder) Decode(data []byte) (interface{}, error) {
+	return decodeNDJSON(data)
+}
+
+func (obj msgPackDecoder) Decode(data []byte) (interface{}, error) {
+	return decodeMsgPack(data)
+}
+
+func decodeJSON(data []byte) (interface{}, error) {
+
+	var tree interface{}
+
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil, err
+	}
+
+	return tree, nil
+}
+
+// json numbers are kept as json.Number, so big integers (e.g. ids above 2^53) are stringified exactly
+func decodeJSONNumber(data []byte) (interface{}, error) {
+
+	var tree interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&tree); err != nil {
+		return nil, err
+	}
+
+	return tree, nil
+}
+
+// every json value on its own line
+func decodeNDJSON(data []byte) (interface{}, error) {
+
+	items := []interface{}{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+
+	for scanner.Scan() {
+
+		line := bytes.TrimSpace(scanner.Bytes())
+
+		if len(line) == 0 {
+			continue
+		}
+
+		item, err := decodeJSON(line)
+
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, item)
+	}
+
+	return items, scanner.Err()
+}
+
+// first record is header, every following record become object keyed by header
+func decodeCSV(data []byte) (interface{}, error) {
+
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+
+	if err != nil {
+		return nil, err
+	}
+
+	items := []interface{}{}
+
+	if len(records) == 0 {
+		return items, nil
+	}
+
+	for _, record := range records[1:] {
+
+		item := map[string]interface{}{}
+
+		for idx, column := range records[0] {
+			if idx < len(record) {
+				item[column] = record[idx]
+			}
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// root element become the tree, child elements become object fields, attributes are prefixed with @,
+// repeated elements become arrays and text of element with children or attributes is stored on #text
+func decodeXML(data []byte) (interface{}, error) {
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	for {
+
+		token, err := decoder.Token()
+
+		if err == io.EOF {
+			return nil, errors.New("No XML Root Element Found")
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if start, ok := token.(xml.StartElement); ok {
+
+			value, err := decodeXMLElement(decoder, start)
+
+			if err != nil {
+				return nil, err
+			}
+
+			return value, nil
+		}
+	}
+}
+
+func decodeXMLElement(decoder *xml.Decoder, start xml.StartElement) (interface{}, error) {
+
+	var text strings.Builder
+
+	children := map[string]interface{}{}
+
+	for _, attr := range start.Attr {
+		children["@"+attr.Name.Local] = attr.Value
+	}
+
+	for {
+
+		token, err := decoder.Token()
+
+		if err != nil {
+			return nil, err
+		}
+
+		switch element := token.(type) {
+		case xml.StartElement:
+
+			value, err := decodeXMLElement(decoder, element)
+
+			if err != nil {
+				return nil, err
+			}
+
+			name := element.Name.Local
+
+			switch existing := children[name].(type) {
+			case nil:
+				children[name] = value
+			case []interface{}:
+				children[name] = append(existing, value)
+			default:
+				children[name] = []interface{}{existing, value}
+			}
+
+		case xml.CharData:
+			text.Write(element)
+
+		case xml.EndElement:
+
+			content := strings.TrimSpace(text.String())
+
+			if len(children) == 0 {
+				return content, nil
+			}
+
+			if content != "" {
+				children["#text"] = content
+			}
+
+			return children, nil
+		}
+	}
+}
+
+// Decode response data with decoder of the aggregator or Content-Type header
+func (obj *Response) Decode() (interface{}, error) {
+	return obj.responseDecoder().Decode([]byte(obj.Data))
+}
+
+// lookup items on decoded response, single repeated element of xml response is returned as one item
+func (obj *Response) lookupItems(tree interface{}, path string) ([]interface{}, bool) {
+
+	if obj.responseDecoder() == XMLDecoder {
+		return lookupXMLItems(tree, path)
+	}
+
+	return lookupItems(tree, path)
+}
+
+// decode response like Decode, json numbers are kept as json.Number
+func (obj *Response) decodeExact() (interface{}, error) {
+
+	decoder := obj.responseDecoder()
+
+	if decoder == JSONDecoder {
+		return decodeJSONNumber([]byte(obj.Data))
+	}
+
+	return decoder.Decode([]byte(obj.Data))
+}
+
+func (obj *Response) responseDecoder() Decoder {
+
+	if obj.decoder == nil {
+		return decoderFor(obj.Header.Get("Content-Type"))
+	}
+
+	return obj.decoder
+}
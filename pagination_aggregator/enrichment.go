@@ -0,0 +1,176 @@
+package paginationaggregator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Enrichment fetch detail of every extracted item and merge it into the item (e.g. detail url of listed stubs)
+type Enrichment struct {
+
+	// Item field which hold full detail url (e.g. url)
+	URLField string
+
+	// Detail url with named placeholders filled with item fields (e.g. https://api/pokemon/{name}), used when URLField is empty
+	URL string
+
+	// Item field to store detail into, empty field merge detail fields into the item
+	Field string
+}
+
+// fetch details of every item in batch within shared concurrency budget, failures are recorded on Response.ItemErrors
+func (obj *PaginationAggregator) enrichItems(tmpBatch []HttpInteraction) {
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+
+	for _, interaction := range tmpBatch {
+
+		exact := obj.exactItems(interaction.Response)
+
+		for idx := range interaction.Response.Items {
+
+			wg.Add(1)
+
+			go func(request *Request, response *Response, idx int) {
+
+				defer wg.Done()
+
+				if err := obj.enrichItem(request.Pointer, response.Items[idx], exact[idx]); err != nil {
+
+					mutex.Lock()
+					defer mutex.Unlock()
+
+					if response.ItemErrors == nil {
+						response.ItemErrors = map[int]error{}
+					}
+
+					response.ItemErrors[idx] = err
+				}
+			}(interaction.Request, interaction.Response, idx)
+		}
+	}
+
+	wg.Wait()
+}
+
+// fetch detail of item on page of given pointer, detail request is recorded with pointer of its page
+func (obj *PaginationAggregator) enrichItem(pointer int, value interface{}, exact interface{}) error {
+
+	item, ok := value.(map[string]interface{})
+
+	if !ok {
+		return errors.New("Item Is Not An Object")
+	}
+
+	requestCtx, cancel := context.WithTimeout(context.Background(), time.Duration(obj.timeout)*time.Second)
+	defer cancel()
+
+	req, err := obj.newEnrichmentRequest(requestCtx, item, exact)
+
+	interaction := obj.roundTrip(req, err, pointer)
+
+	if interaction.Response.Error != nil {
+		return interaction.Response.Error
+	}
+
+	detail, err := interaction.Response.Decode()
+
+	if err != nil {
+		return err
+	}
+
+	if obj.enrichment.Field != "" {
+		item[obj.enrichment.Field] = detail
+		return nil
+	}
+
+	fields, ok := detail.(map[string]interface{})
+
+	if !ok {
+		return errors.New("Detail Is Not An Object")
+	}
+
+	for key, value := range fields {
+		item[key] = value
+	}
+
+	return nil
+}
+
+func (obj *PaginationAggregator) newEnrichmentRequest(ctx context.Context, item map[string]interface{}, exact interface{}) (*http.Request, error) {
+
+	var detailURL string
+
+	if obj.enrichment.URLField != "" {
+
+		value, ok := lookupPath(item, obj.enrichment.URLField)
+
+		if !ok {
+			return nil, fmt.Errorf("No Detail URL Found On Item Field %s", obj.enrichment.URLField)
+		}
+
+		detailURL = fmt.Sprint(value)
+	} else {
+
+		fields := itemFields(item, exact)
+
+		for _, placeholder := range obj.enrichmentURL.placeholders() {
+			if _, ok := fields[placeholder]; !ok {
+				return nil, fmt.Errorf("No Item Field Found For Detail URL Placeholder {%s}", placeholder)
+			}
+		}
+
+		detailURL = obj.enrichmentURL.render(0, fields)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, detailURL, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range obj.headers {
+		req.Header.Set(key, value)
+	}
+
+	return req, nil
+}
+
+// validate enrichment and parse its detail url template once, placeholders are filled with item fields
+func validateEnrichment(enrichment *Enrichment, itemsPath string) (*urlTemplate, error) {
+
+	if enrichment == nil {
+		return nil, nil
+	}
+
+	if itemsPath == "" {
+		return nil, errors.New("No Items Path Found For Enrichment")
+	}
+
+	if enrichment.URLField == "" && enrichment.URL == "" {
+		return nil, errors.New("No Detail URL Found For Enrichment")
+	}
+
+	if enrichment.URLField != "" {
+		return nil, nil
+	}
+
+	template := parseTemplate(enrichment.URL)
+
+	if len(template.segments) == 0 {
+		return nil, errors.New("No Item Field Placeholder Found On Detail URL")
+	}
+
+	known := map[string]bool{}
+
+	for _, placeholder := range template.placeholders() {
+		known[placeholder] = true
+	}
+
+	return template, template.validate(known)
+}
@@ -0,0 +1,185 @@
+package paginationaggregator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	DEFAULT_POLL_INTERVAL     = time.Second
+	DEFAULT_MAX_POLL_INTERVAL = 30 * time.Second
+)
+
+// JobRequest build request of export job, job is empty when job is started
+type JobRequest func(ctx context.Context, job string) (*http.Request, error)
+
+// ExportJob start asynchronous export, poll it until it is ready and fetch its result chunks concurrently instead of URL pages
+type ExportJob struct {
+
+	// Override this function to build request which starts export job
+	Start JobRequest
+
+	// Override this function to build request which polls status of started job
+	Poll JobRequest
+
+	// Dot separated path of job id in start response
+	JobPath string
+
+	// Dot separated path of job status in poll response
+	StatusPath string
+
+	// Status of finished job
+	ReadyStatus string
+
+	// Statuses of failed job, polling stops with error
+	FailedStatuses []string
+
+	// Dot separated path of chunk URLs in poll response of ready job, relative URLs are resolved against poll URL
+	ChunksPath string
+
+	// First poll interval doubled on every poll, default 1s
+	PollInterval time.Duration
+
+	// Max poll interval, default 30s
+	MaxPollInterval time.Duration
+
+	BasePlugin
+}
+
+func (obj *ExportJob) BeforeRun(state *State) error {
+
+	start := obj.send(state, obj.Start, "")
+
+	if start.Response.Error != nil {
+		return start.Response.Error
+	}
+
+	job, err := lookupString(start.Response, obj.JobPath)
+
+	if err != nil {
+		return err
+	}
+
+	interval := obj.pollInterval()
+
+	for {
+
+		poll := obj.send(state, obj.Poll, job)
+
+		if poll.Response.Error != nil {
+			return poll.Response.Error
+		}
+
+		status, err := lookupString(poll.Response, obj.StatusPath)
+
+		if err != nil {
+			return err
+		}
+
+		if status == obj.ReadyStatus {
+			return obj.feed(state, poll)
+		}
+
+		for _, failed := range obj.FailedStatuses {
+			if status == failed {
+				return errors.New("Export Job Failed With Status " + status)
+			}
+		}
+
+		if err := state.Wait(interval); err != nil {
+			return err
+		}
+
+		if interval *= 2; interval > obj.maxPollInterval() {
+			interval = obj.maxPollInterval()
+		}
+	}
+}
+
+// feed chunk URLs of ready job as keys fetched concurrently
+func (obj *ExportJob) feed(state *State, poll HttpInteraction) error {
+
+	tree, err := poll.Response.Decode()
+
+	if err != nil {
+		return err
+	}
+
+	chunks, ok := poll.Response.lookupItems(tree, obj.ChunksPath)
+
+	if !ok {
+		return errors.New("No Export Job Chunks Found")
+	}
+
+	var urls []string
+
+	for _, chunk := range chunks {
+
+		location, err := poll.Request.HttpRequest.URL.Parse(fmt.Sprint(chunk))
+
+		if err != nil {
+			return err
+		}
+
+		urls = append(urls, location.String())
+	}
+
+	return state.SetURLs(urls...)
+}
+
+func (obj *ExportJob) send(state *State, builder JobRequest, job string) HttpInteraction {
+	return state.Send(func(ctx context.Context) (*http.Request, error) {
+		return builder(ctx, job)
+	})
+}
+
+func (obj *ExportJob) pollInterval() time.Duration {
+
+	if obj.PollInterval == 0 {
+		return DEFAULT_POLL_INTERVAL
+	}
+
+	return obj.PollInterval
+}
+
+func (obj *ExportJob) maxPollInterval() time.Duration {
+
+	if obj.MaxPollInterval == 0 {
+		return DEFAULT_MAX_POLL_INTERVAL
+	}
+
+	return obj.MaxPollInterval
+}
+
+func (obj *ExportJob) validate() error {
+
+	if obj.Start == nil || obj.Poll == nil {
+		return errors.New("No Export Job Start Or Poll Request Found")
+	}
+
+	if obj.JobPath == "" || obj.StatusPath == "" || obj.ReadyStatus == "" || obj.ChunksPath == "" {
+		return errors.New("No Export Job Path Or Ready Status Found")
+	}
+
+	return nil
+}
+
+func lookupString(response *Response, path string) (string, error) {
+
+	tree, err := response.decodeExact()
+
+	if err != nil {
+		return "", err
+	}
+
+	value, ok := lookupPath(tree, path)
+
+	if !ok || value == nil {
+		return "", errors.New("No Value Found On Path " + path)
+	}
+
+	return stringify(value), nil
+}
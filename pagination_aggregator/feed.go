@@ -0,0 +1,116 @@
+package paginationaggregator
+
+import (
+	"encoding/xml"
+	"net/http"
+)
+
+// Feed follow paging links of Atom and RSS feeds (RFC 5005) and yield entries as items
+type Feed struct {
+
+	// Link relations followed to next page in order of preference, default next and prev-archive
+	Rels []string
+}
+
+type feedLink struct {
+	Rel  string `xml:"rel,attr"`
+	Href string `xml:"href,attr"`
+}
+
+// links of atom feed or atom:link elements inside rss channel
+type feedDocument struct {
+	Links   []feedLink `xml:"http://www.w3.org/2005/Atom link"`
+	Channel struct {
+		Links []feedLink `xml:"http://www.w3.org/2005/Atom link"`
+	} `xml:"channel"`
+}
+
+func (obj *Feed) Apply(req *http.Request, cursor string) error {
+
+	if cursor == "" {
+		return nil
+	}
+
+	next, err := req.URL.Parse(cursor)
+
+	if err != nil {
+		return err
+	}
+
+	req.URL = next
+	req.Host = next.Host
+
+	return nil
+}
+
+func (obj *Feed) Next(interaction *HttpInteraction) (string, bool) {
+
+	data := []byte(interaction.Response.Data)
+
+	if interaction.Response.Items == nil {
+		interaction.Response.Items = feedEntries(data)
+	}
+
+	var document feedDocument
+
+	if err := xml.Unmarshal(data, &document); err != nil {
+		interaction.Response.Error = err
+		return "", false
+	}
+
+	links := append(document.Links, document.Channel.Links...)
+
+	for _, rel := range obj.rels() {
+		for _, link := range links {
+			if link.Rel == rel && link.Href != "" {
+				return resolveFeedLink(interaction, link.Href)
+			}
+		}
+	}
+
+	return "", false
+}
+
+// relative link is resolved against document which contains it, not against configured URL
+func resolveFeedLink(interaction *HttpInteraction, href string) (string, bool) {
+
+	if interaction.Request == nil || interaction.Request.HttpRequest == nil {
+		return href, true
+	}
+
+	next, err := interaction.Request.HttpRequest.URL.Parse(href)
+
+	if err != nil {
+		interaction.Response.Error = err
+		return "", false
+	}
+
+	return next.String(), true
+}
+
+func (obj *Feed) rels() []string {
+
+	if len(obj.Rels) == 0 {
+		return []string{"next", "prev-archive"}
+	}
+
+	return obj.Rels
+}
+
+// entries of atom feed, rss 2.0 channel items or rss 1.0 items
+func feedEntries(data []byte) []interface{} {
+
+	tree, err := XMLDecoder.Decode(data)
+
+	if err != nil {
+		return nil
+	}
+
+	for _, path := range []string{"entry", "channel.item", "item"} {
+		if items, ok := lookupXMLItems(tree, path); ok {
+			return items
+		}
+	}
+
+	return []interface{}{}
+}
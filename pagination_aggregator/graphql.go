@@ -0,0 +1,148 @@
+package paginationaggregator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const DEFAULT_GRAPHQL_CURSOR_VARIABLE = "after"
+
+// GraphQL connection (Relay) pagination which follow pageInfo { hasNextPage endCursor }
+type GraphQL struct {
+
+	// GraphQL query which accept cursor variable (e.g. query($after: String) { ... })
+	Query string
+
+	// Query variables sent on every page
+	Variables map[string]interface{}
+
+	// Name of cursor variable, default "after"
+	CursorVariable string
+
+	// Dot separated path of pageInfo object in response (e.g. data.repository.issues.pageInfo)
+	PageInfoPath string
+
+	// Dot separated path of rate limit or cost object in response (e.g. data.rateLimit)
+	RateLimitPath string
+
+	// Override this function to pace next request based on value at RateLimitPath
+	Pace func(rateLimit interface{}) time.Duration
+}
+
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+func (obj *GraphQL) Apply(req *http.Request, cursor string) error {
+
+	variables := map[string]interface{}{}
+
+	for key, value := range obj.Variables {
+		variables[key] = value
+	}
+
+	variables[obj.cursorVariable()] = nil
+
+	if cursor != "" {
+		variables[obj.cursorVariable()] = cursor
+	}
+
+	body, err := json.Marshal(graphQLRequest{Query: obj.Query, Variables: variables})
+
+	if err != nil {
+		return err
+	}
+
+	req.Method = http.MethodPost
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	return nil
+}
+
+func (obj *GraphQL) Next(interaction *HttpInteraction) (string, bool) {
+
+	tree, err := interaction.Response.Decode()
+
+	if err != nil {
+		interaction.Response.Error = err
+		return "", false
+	}
+
+	// GraphQL report errors with http status 200
+	if graphQLErrors, ok := lookupItems(tree, "errors"); ok && len(graphQLErrors) > 0 {
+		interaction.Response.Error = graphQLError(graphQLErrors)
+		return "", false
+	}
+
+	obj.pace(tree)
+
+	hasNextPage, _ := lookupPath(tree, obj.PageInfoPath+".hasNextPage")
+	endCursor, _ := lookupPath(tree, obj.PageInfoPath+".endCursor")
+
+	if next, ok := hasNextPage.(bool); !ok || !next {
+		return "", false
+	}
+
+	cursor, ok := endCursor.(string)
+
+	return cursor, ok
+}
+
+func (obj *GraphQL) pace(tree interface{}) {
+
+	if obj.Pace == nil || obj.RateLimitPath == "" {
+		return
+	}
+
+	if rateLimit, ok := lookupPath(tree, obj.RateLimitPath); ok {
+		time.Sleep(obj.Pace(rateLimit))
+	}
+}
+
+func (obj *GraphQL) cursorVariable() string {
+
+	if obj.CursorVariable == "" {
+		return DEFAULT_GRAPHQL_CURSOR_VARIABLE
+	}
+
+	return obj.CursorVariable
+}
+
+func (obj *GraphQL) validate() error {
+
+	if obj.Query == "" {
+		return errors.New("No GraphQL Query Found")
+	}
+
+	if obj.PageInfoPath == "" {
+		return errors.New("No GraphQL PageInfo Path Found")
+	}
+
+	return nil
+}
+
+func graphQLError(graphQLErrors []interface{}) error {
+
+	var messages []string
+
+	for _, val := range graphQLErrors {
+
+		if detail, ok := val.(map[string]interface{}); ok {
+			messages = append(messages, fmt.Sprint(detail["message"]))
+		}
+	}
+
+	return errors.New(strings.Join(messages, "; "))
+}
@@ -0,0 +1,58 @@
+package paginationaggregator
+
+import (
+	"errors"
+	"net/http"
+)
+
+// HeaderCursor read continuation token from response header (e.g. x-ms-continuation, X-Next-Page-Token)
+// and pass it on next request, pagination stops when token is absent
+type HeaderCursor struct {
+
+	// Response header which hold continuation token
+	ResponseHeader string
+
+	// Query param to pass token on next request
+	QueryParam string
+
+	// Request header to pass token on next request
+	RequestHeader string
+}
+
+func (obj *HeaderCursor) Apply(req *http.Request, cursor string) error {
+
+	if cursor == "" {
+		return nil
+	}
+
+	if obj.QueryParam != "" {
+		setQueryParam(req.URL, obj.QueryParam, cursor)
+	}
+
+	if obj.RequestHeader != "" {
+		req.Header.Set(obj.RequestHeader, cursor)
+	}
+
+	return nil
+}
+
+func (obj *HeaderCursor) Next(interaction *HttpInteraction) (string, bool) {
+
+	token := interaction.Response.Header.Get(obj.ResponseHeader)
+
+	return token, token != ""
+}
+
+func (obj *HeaderCursor) validate() error {
+
+	if obj.ResponseHeader == "" {
+		return errors.New("No Cursor Response Header Found")
+	}
+
+	// token which is never sent repeats first page
+	if obj.QueryParam == "" && obj.RequestHeader == "" {
+		return errors.New("No Cursor Query Param Or Request Header Found")
+	}
+
+	return nil
+}
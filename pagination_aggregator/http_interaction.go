@@ -9,14 +9,28 @@ type Response struct {
 	StatusText string
 	Error      error
 	Data       string
+	Header     http.Header
+	Items      []interface{}
+	ItemErrors map[int]error
+	decoder    Decoder
+
+	// next page token returned by Transport
+	nextPageToken string
 }
 
 type Request struct {
+	Source      string
 	Pointer     int
+	Key         string
+	Cursor      string
+	Window      *Window
+	Partition   string
+	Params      map[string]string
 	HttpRequest *http.Request
 }
 
 type HttpInteraction struct {
 	Response *Response
 	Request  *Request
+	Parent   *ParentItem
 }
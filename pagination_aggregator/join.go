@@ -0,0 +1,456 @@
+package paginationaggregator
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"sort"
+	"sync"
+)
+
+type JoinType int
+
+const (
+	INNER_JOIN JoinType = iota
+	LEFT_JOIN
+	FULL_JOIN
+)
+
+const (
+	DEFAULT_JOIN_MAX_ITEMS_IN_MEMORY = 100000
+	DEFAULT_JOIN_BUCKETS             = 16
+)
+
+// JoinSide is single aggregation joined by key extracted from every item on Response.Items
+type JoinSide struct {
+	Name       string
+	Aggregator *PaginationAggregator
+	Key        func(item interface{}) string
+}
+
+// JoinedRecord hold joined items keyed by side name, missing side on left or full join is nil
+type JoinedRecord struct {
+	Key   string
+	Items map[string]interface{}
+}
+
+// Join run aggregations concurrently and join its items by key, first side is the left side.
+// Items are spilled into bucket files when a side hold more than MaxItemsInMemory items
+type Join struct {
+	Type  JoinType
+	Sides []JoinSide
+
+	// Max number of items kept in memory for every side, default 100000
+	MaxItemsInMemory int
+
+	// Directory of spilled bucket files, default os temp dir
+	SpillDir string
+
+	// Number of bucket files for every spilled side, default 16
+	Buckets int
+}
+
+type joinEntry struct {
+	Key  string      `json:"key"`
+	Item interface{} `json:"item"`
+}
+
+// joinStore collect items of single side and spill them into bucket files by key hash
+type joinStore struct {
+	mutex   sync.Mutex
+	limit   int
+	dir     string
+	buckets int
+	count   int
+	items   map[string][]interface{}
+	files   []*os.File
+	writers []*bufio.Writer
+}
+
+func NewJoin(joinType JoinType, sides ...JoinSide) *Join {
+	return &Join{
+		Type:  joinType,
+		Sides: sides,
+	}
+}
+
+// Run fetch every side and emit joined records, returned emit error stop the join
+func (obj *Join) Run(emit func(record JoinedRecord) error) error {
+
+	if err := obj.validate(); err != nil {
+		return err
+	}
+
+	stores := make([]*joinStore, len(obj.Sides))
+
+	for idx := range obj.Sides {
+		stores[idx] = obj.newStore()
+	}
+
+	defer func() {
+		for _, store := range stores {
+			store.close()
+		}
+	}()
+
+	if err := obj.fetch(stores); err != nil {
+		return err
+	}
+
+	spilled := false
+
+	for _, store := range stores {
+		spilled = spilled || store.spilled()
+	}
+
+	if !spilled {
+		return obj.joinBucket(stores, func(store *joinStore) (map[string][]interface{}, error) {
+			return store.items, nil
+		}, emit)
+	}
+
+	for _, store := range stores {
+		if err := store.spill(); err != nil {
+			return err
+		}
+	}
+
+	for bucket := 0; bucket < obj.buckets(); bucket++ {
+
+		err := obj.joinBucket(stores, func(store *joinStore) (map[string][]interface{}, error) {
+			return store.load(bucket)
+		}, emit)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// run every side aggregation concurrently while storing its items batch by batch
+func (obj *Join) fetch(stores []*joinStore) error {
+
+	var wg sync.WaitGroup
+
+	errs := make([]error, len(obj.Sides))
+
+	for idx, side := range obj.Sides {
+
+		wg.Add(1)
+
+		go func(idx int, side JoinSide) {
+
+			defer wg.Done()
+
+			// aggregator is owned by caller, its result and hook are restored after side is fetched
+			discardResult, batchHook := side.Aggregator.discardResult, side.Aggregator.batchHook
+
+			defer func() {
+				side.Aggregator.discardResult = discardResult
+				side.Aggregator.batchHook = batchHook
+			}()
+
+			side.Aggregator.discardResult = true
+			side.Aggregator.batchHook = func(batchResult []HttpInteraction) error {
+
+				for _, val := range batchResult {
+					for _, item := range val.Response.Items {
+						if err := stores[idx].add(side.Key(item), item); err != nil {
+							return err
+						}
+					}
+				}
+
+				return nil
+			}
+
+			_, errs[idx] = side.Aggregator.Get()
+		}(idx, side)
+	}
+
+	wg.Wait()
+
+	for idx, err := range errs {
+		if err != nil {
+			return fmt.Errorf("Join Side %s: %w", obj.Sides[idx].Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (obj *Join) joinBucket(stores []*joinStore, load func(store *joinStore) (map[string][]interface{}, error), emit func(record JoinedRecord) error) error {
+
+	sides := make([]map[string][]interface{}, len(stores))
+
+	for idx, store := range stores {
+
+		items, err := load(store)
+
+		if err != nil {
+			return err
+		}
+
+		sides[idx] = items
+	}
+
+	for _, key := range obj.keys(sides) {
+
+		records := []map[string]interface{}{{}}
+
+		for idx, side := range obj.Sides {
+
+			matches := sides[idx][key]
+
+			if len(matches) == 0 {
+				matches = []interface{}{nil}
+			}
+
+			var expanded []map[string]interface{}
+
+			for _, record := range records {
+				for _, match := range matches {
+
+					next := map[string]interface{}{side.Name: match}
+
+					for name, item := range record {
+						next[name] = item
+					}
+
+					expanded = append(expanded, next)
+				}
+			}
+
+			records = expanded
+		}
+
+		for _, record := range records {
+			if err := emit(JoinedRecord{Key: key, Items: record}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// keys to emit in sorted order according to join type
+func (obj *Join) keys(sides []map[string][]interface{}) []string {
+
+	var keys []string
+
+	candidates := sides[0]
+
+	if obj.Type == FULL_JOIN {
+
+		candidates = map[string][]interface{}{}
+
+		for _, side := range sides {
+			for key := range side {
+				candidates[key] = nil
+			}
+		}
+	}
+
+	for key := range candidates {
+
+		matched := true
+
+		if obj.Type == INNER_JOIN {
+			for _, side := range sides[1:] {
+				matched = matched && len(side[key]) > 0
+			}
+		}
+
+		if matched {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func (obj *Join) newStore() *joinStore {
+
+	store := &joinStore{
+		limit:   obj.MaxItemsInMemory,
+		dir:     obj.SpillDir,
+		buckets: obj.buckets(),
+		items:   map[string][]interface{}{},
+	}
+
+	if store.limit == 0 {
+		store.limit = DEFAULT_JOIN_MAX_ITEMS_IN_MEMORY
+	}
+
+	return store
+}
+
+func (obj *Join) buckets() int {
+
+	if obj.Buckets == 0 {
+		return DEFAULT_JOIN_BUCKETS
+	}
+
+	return obj.Buckets
+}
+
+func (obj *Join) validate() error {
+
+	if len(obj.Sides) < 2 {
+		return errors.New("Join Need At Least Two Sides")
+	}
+
+	names := map[string]bool{}
+
+	for _, side := range obj.Sides {
+
+		if side.Aggregator == nil || side.Key == nil {
+			return errors.New("No Aggregator Or Key Function Found On Join Side " + side.Name)
+		}
+
+		if side.Aggregator.itemsPath == "" {
+			return errors.New("No Items Path Found On Join Side " + side.Name)
+		}
+
+		if names[side.Name] {
+			return errors.New("Duplicate Join Side Name " + side.Name)
+		}
+
+		names[side.Name] = true
+	}
+
+	return nil
+}
+
+func (obj *joinStore) add(key string, item interface{}) error {
+
+	obj.mutex.Lock()
+	defer obj.mutex.Unlock()
+
+	if obj.spilled() {
+		return obj.write(key, item)
+	}
+
+	obj.items[key] = append(obj.items[key], item)
+	obj.count++
+
+	if obj.count > obj.limit {
+		return obj.spillLocked()
+	}
+
+	return nil
+}
+
+func (obj *joinStore) spilled() bool {
+	return obj.files != nil
+}
+
+func (obj *joinStore) spill() error {
+
+	obj.mutex.Lock()
+	defer obj.mutex.Unlock()
+
+	return obj.spillLocked()
+}
+
+// move in memory items into bucket files, following items are written directly into bucket files
+func (obj *joinStore) spillLocked() error {
+
+	if !obj.spilled() {
+
+		for bucket := 0; bucket < obj.buckets; bucket++ {
+
+			file, err := os.CreateTemp(obj.dir, "pagination-join-*")
+
+			if err != nil {
+				return err
+			}
+
+			obj.files = append(obj.files, file)
+			obj.writers = append(obj.writers, bufio.NewWriter(file))
+		}
+	}
+
+	for key, items := range obj.items {
+		for _, item := range items {
+			if err := obj.write(key, item); err != nil {
+				return err
+			}
+		}
+	}
+
+	obj.items = map[string][]interface{}{}
+
+	return nil
+}
+
+func (obj *joinStore) write(key string, item interface{}) error {
+
+	data, err := json.Marshal(joinEntry{Key: key, Item: item})
+
+	if err != nil {
+		return err
+	}
+
+	writer := obj.writers[bucketOf(key, obj.buckets)]
+
+	if _, err = writer.Write(append(data, '\n')); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (obj *joinStore) load(bucket int) (map[string][]interface{}, error) {
+
+	items := map[string][]interface{}{}
+
+	if err := obj.writers[bucket].Flush(); err != nil {
+		return nil, err
+	}
+
+	file := obj.files[bucket]
+
+	if _, err := file.Seek(0, 0); err != nil {
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+
+	for scanner.Scan() {
+
+		var entry joinEntry
+
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, err
+		}
+
+		items[entry.Key] = append(items[entry.Key], entry.Item)
+	}
+
+	return items, scanner.Err()
+}
+
+func (obj *joinStore) close() {
+	for _, file := range obj.files {
+		file.Close()
+		os.Remove(file.Name())
+	}
+}
+
+func bucketOf(key string, buckets int) int {
+
+	hash := fnv.New32a()
+	hash.Write([]byte(key))
+
+	return int(hash.Sum32() % uint32(buckets))
+}
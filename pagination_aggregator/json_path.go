@@ -0,0 +1,90 @@
+package paginationaggregator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// lookup value on decoded json using dot separated path (e.g. data.items.0.id), empty path or "." returns root
+func lookupPath(tree interface{}, path string) (interface{}, bool) {
+
+	if path == "" || path == "." {
+		return tree, true
+	}
+
+	current := tree
+
+	for _, key := range strings.Split(path, ".") {
+
+		switch node := current.(type) {
+		case map[string]interface{}:
+
+			value, ok := node[key]
+
+			if !ok {
+				return nil, false
+			}
+
+			current = value
+
+		case []interface{}:
+
+			idx, err := strconv.Atoi(key)
+
+			if err != nil || idx < 0 || idx >= len(node) {
+				return nil, false
+			}
+
+			current = node[idx]
+
+		default:
+			return nil, false
+		}
+	}
+
+	return current, true
+}
+
+// lookup array value on decoded json using dot separated path
+func lookupItems(tree interface{}, path string) ([]interface{}, bool) {
+
+	value, ok := lookupPath(tree, path)
+
+	if !ok {
+		return nil, false
+	}
+
+	node, ok := value.([]interface{})
+
+	return node, ok
+}
+
+// lookup array value on decoded xml, single repeated element is decoded as object and returned as one item
+func lookupXMLItems(tree interface{}, path string) ([]interface{}, bool) {
+
+	value, ok := lookupPath(tree, path)
+
+	if !ok {
+		return nil, false
+	}
+
+	if node, ok := value.(map[string]interface{}); ok {
+		return []interface{}{node}, true
+	}
+
+	node, ok := value.([]interface{})
+
+	return node, ok
+}
+
+// format decoded value as text, json numbers are written without exponent (e.g. 1234567 instead of 1.234567e+06).
+// Values decoded with json.Number are written as they are served
+func stringify(value interface{}) string {
+
+	if number, ok := value.(float64); ok {
+		return strconv.FormatFloat(number, 'f', -1, 64)
+	}
+
+	return fmt.Sprint(value)
+}
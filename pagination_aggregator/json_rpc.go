@@ -0,0 +1,408 @@
+package paginationaggregator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const JSON_RPC_VERSION = "2.0"
+
+// JsonRPC wrap every page into JSON-RPC 2.0 request envelope sent with POST to URL
+type JsonRPC struct {
+
+	// Remote method of every page (e.g. eth_getLogs, list_transactions)
+	Method string
+
+	// Json params with named placeholders, same as URL placeholders (e.g. {"offset": {offset}, "limit": {limit}})
+	Params string
+
+	// Number of pages sent as one batch array on single http round trip, default 1
+	BatchSize int
+
+	// Dot separated path of next cursor in result, pages follow {cursor} sequentially when it is set
+	CursorPath string
+}
+
+// JsonRPCError is error object of JSON-RPC response, recorded as page failure
+type JsonRPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (obj *JsonRPCError) Error() string {
+	return fmt.Sprintf("JSON-RPC Error %d: %s", obj.Code, obj.Message)
+}
+
+type jsonRPCRequest struct {
+	JsonRPC string          `json:"jsonrpc"`
+	ID      int64           `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type jsonRPCResponse struct {
+	ID     json.RawMessage `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *JsonRPCError   `json:"error"`
+}
+
+type jsonRPCPage struct {
+	pointer int
+	cursor  string
+	id      int64
+}
+
+var jsonRPCID int64
+
+// fetch pages with JSON-RPC envelopes, by cursor when CursorPath is set or by page pointer otherwise
+func (obj *PaginationAggregator) getByJsonRPC() ([]HttpInteraction, error) {
+
+	if obj.jsonRPC.CursorPath != "" {
+		return obj.getByJsonRPCCursor()
+	}
+
+	for pointer := obj.start; obj.boundary == 0 || pointer <= obj.boundary; {
+
+		var rounds [][]jsonRPCPage
+
+		// every batch send Concurrent round trips, every round trip carry BatchSize pages
+		for len(rounds) < obj.concurrent && (obj.boundary == 0 || pointer <= obj.boundary) {
+
+			var pages []jsonRPCPage
+
+			for len(pages) < obj.jsonRPC.batchSize() && (obj.boundary == 0 || pointer <= obj.boundary) {
+				pages = append(pages, jsonRPCPage{pointer: pointer})
+				pointer++
+			}
+
+			rounds = append(rounds, pages)
+		}
+
+		var wg sync.WaitGroup
+
+		results := make([][]HttpInteraction, len(rounds))
+
+		for idx, pages := range rounds {
+
+			wg.Add(1)
+
+			go func(idx int, pages []jsonRPCPage) {
+				defer wg.Done()
+				results[idx] = obj.callJsonRPC(pages)
+			}(idx, pages)
+		}
+
+		wg.Wait()
+
+		var tmpBatch []HttpInteraction
+
+		last := false
+
+		for _, interactions := range results {
+			for _, interaction := range interactions {
+				last = last || (obj.boundary == 0 && isLastJsonRPCPage(interaction))
+				tmpBatch = append(tmpBatch, interaction)
+			}
+		}
+
+		if err := obj.collect(tmpBatch); err != nil {
+			return obj.finish(err)
+		}
+
+		if last || (obj.boundary != 0 && pointer > obj.boundary) {
+			break
+		}
+
+		if obj.ctx != nil && obj.ctx.Err() != nil {
+			return nil, obj.ctx.Err()
+		}
+
+		time.Sleep(time.Duration(obj.delayBetweenBatch) * time.Second)
+	}
+
+	return obj.result, nil
+}
+
+func (obj *PaginationAggregator) getByJsonRPCCursor() ([]HttpInteraction, error) {
+
+	var tmpBatch []HttpInteraction
+
+	cursor := ""
+
+	for pointer := obj.start; obj.boundary == 0 || pointer <= obj.boundary; pointer++ {
+
+		interaction := obj.callJsonRPC([]jsonRPCPage{{pointer: pointer, cursor: cursor}})[0]
+
+		next, ok := "", false
+
+		if interaction.Response.Error == nil {
+			next, ok = jsonRPCCursor(interaction.Response.Data, obj.jsonRPC.CursorPath)
+		}
+
+		tmpBatch = append(tmpBatch, interaction)
+
+		last := !ok || interaction.Response.Error != nil
+
+		if len(tmpBatch) == obj.concurrent || last || pointer == obj.boundary {
+
+			if err := obj.collect(tmpBatch); err != nil {
+				return obj.finish(err)
+			}
+
+			tmpBatch = nil
+		}
+
+		if last {
+			break
+		}
+
+		cursor = next
+
+		if obj.ctx != nil && obj.ctx.Err() != nil {
+			return nil, obj.ctx.Err()
+		}
+	}
+
+	return obj.result, nil
+}
+
+// send pages on single http round trip, single envelope for one page and batch array for more pages
+func (obj *PaginationAggregator) callJsonRPC(pages []jsonRPCPage) []HttpInteraction {
+
+	requestCtx, cancel := context.WithTimeout(context.Background(), time.Duration(obj.timeout)*time.Second)
+	defer cancel()
+
+	var envelopes []jsonRPCRequest
+
+	for idx := range pages {
+
+		pages[idx].id = atomic.AddInt64(&jsonRPCID, 1)
+
+		envelope := jsonRPCRequest{JsonRPC: JSON_RPC_VERSION, ID: pages[idx].id, Method: obj.jsonRPC.Method}
+
+		if obj.jsonRPCParams != nil {
+			values := obj.templateValues(pages[idx].pointer, pages[idx].cursor)
+			envelope.Params = json.RawMessage(obj.jsonRPCParams.render(pages[idx].pointer, values))
+		}
+
+		envelopes = append(envelopes, envelope)
+	}
+
+	var payload []byte
+	var err error
+
+	if len(envelopes) == 1 {
+		payload, err = json.Marshal(envelopes[0])
+	} else {
+		payload, err = json.Marshal(envelopes)
+	}
+
+	var req *http.Request
+
+	if err == nil {
+		req, err = http.NewRequestWithContext(requestCtx, http.MethodPost, obj.buildURL(pages[0].pointer, obj.templateValues(pages[0].pointer, pages[0].cursor)), bytes.NewReader(payload))
+	}
+
+	if err != nil {
+		return obj.jsonRPCFailures(req, pages, http.StatusInternalServerError, err, nil)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	for key, value := range obj.headers {
+		req.Header.Set(key, value)
+	}
+
+	if err := obj.beforeRequest(req); err != nil {
+		return obj.jsonRPCFailures(req, pages, http.StatusInternalServerError, err, nil)
+	}
+
+	resp, err := obj.do(req)
+
+	if err != nil {
+		return obj.jsonRPCFailures(req, pages, http.StatusInternalServerError, err, nil)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 && resp.StatusCode <= 599 {
+		return obj.jsonRPCFailures(req, pages, resp.StatusCode, errors.New(http.StatusText(resp.StatusCode)), resp.Header)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+
+	if err != nil {
+		return obj.jsonRPCFailures(req, pages, http.StatusInternalServerError, err, resp.Header)
+	}
+
+	responses, err := decodeJsonRPCResponses(data)
+
+	if err != nil {
+		return obj.jsonRPCFailures(req, pages, resp.StatusCode, err, resp.Header)
+	}
+
+	// single error object without id answer whole batch (e.g. invalid request)
+	if len(responses) == 1 && responses[0].Error != nil && (len(responses[0].ID) == 0 || string(responses[0].ID) == "null") {
+		return obj.jsonRPCFailures(req, pages, resp.StatusCode, responses[0].Error, resp.Header)
+	}
+
+	byID := map[string]jsonRPCResponse{}
+
+	for _, response := range responses {
+		byID[string(response.ID)] = response
+	}
+
+	var interactions []HttpInteraction
+
+	for _, page := range pages {
+
+		response, ok := byID[strconv.FormatInt(page.id, 10)]
+
+		var interaction HttpInteraction
+
+		switch {
+		case !ok:
+			interaction = obj.failure(req, page.pointer, resp.StatusCode, errors.New("No JSON-RPC Response Found"), resp.Header)
+		case response.Error != nil:
+			interaction = obj.failure(req, page.pointer, resp.StatusCode, response.Error, resp.Header)
+		default:
+
+			result := &Response{
+				Status:     resp.StatusCode,
+				StatusText: resp.Status,
+				Data:       string(response.Result),
+				Header:     resp.Header,
+				decoder:    JSONDecoder,
+			}
+
+			result.Items = obj.extractItems(result)
+
+			interaction = HttpInteraction{
+				Request: &Request{
+					HttpRequest: req,
+					Pointer:     page.pointer,
+				},
+				Response: result,
+			}
+		}
+
+		interaction.Request.Cursor = page.cursor
+		obj.afterResponse(&interaction)
+		interactions = append(interactions, interaction)
+	}
+
+	return interactions
+}
+
+func (obj *PaginationAggregator) jsonRPCFailures(req *http.Request, pages []jsonRPCPage, status int, err error, header http.Header) []HttpInteraction {
+
+	var interactions []HttpInteraction
+
+	for _, page := range pages {
+		interaction := obj.failure(req, page.pointer, status, err, header)
+		interaction.Request.Cursor = page.cursor
+		obj.afterResponse(&interaction)
+		interactions = append(interactions, interaction)
+	}
+
+	return interactions
+}
+
+func decodeJsonRPCResponses(data []byte) ([]jsonRPCResponse, error) {
+
+	data = bytes.TrimSpace(data)
+
+	if len(data) > 0 && data[0] == '[' {
+
+		var responses []jsonRPCResponse
+
+		err := json.Unmarshal(data, &responses)
+
+		return responses, err
+	}
+
+	var response jsonRPCResponse
+
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, err
+	}
+
+	return []jsonRPCResponse{response}, nil
+}
+
+// without boundary pages stop on failure or empty result
+func isLastJsonRPCPage(interaction HttpInteraction) bool {
+
+	if interaction.Response.Error != nil {
+		return true
+	}
+
+	if interaction.Response.Items != nil {
+		return len(interaction.Response.Items) == 0
+	}
+
+	switch string(bytes.TrimSpace([]byte(interaction.Response.Data))) {
+	case "", "null", "[]", "{}":
+		return true
+	}
+
+	return false
+}
+
+func jsonRPCCursor(result string, path string) (string, bool) {
+
+	tree, err := decodeJSONNumber([]byte(result))
+
+	if err != nil {
+		return "", false
+	}
+
+	value, ok := lookupPath(tree, path)
+
+	if !ok || value == nil {
+		return "", false
+	}
+
+	cursor := stringify(value)
+
+	return cursor, cursor != ""
+}
+
+func (obj *JsonRPC) batchSize() int {
+
+	if obj.BatchSize < 1 {
+		return 1
+	}
+
+	return obj.BatchSize
+}
+
+// validate JSON-RPC and parse its params template, nil template is returned without params
+func (obj *JsonRPC) validate(known map[string]bool) (*urlTemplate, error) {
+
+	if obj.Method == "" {
+		return nil, errors.New("No JSON-RPC Method Found")
+	}
+
+	if obj.Params == "" {
+		return nil, nil
+	}
+
+	params := newBodyTemplate(obj.Params)
+
+	if err := params.validate(known); err != nil {
+		return nil, err
+	}
+
+	return params, nil
+}
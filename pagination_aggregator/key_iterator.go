@@ -0,0 +1,131 @@
+package paginationaggregator
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const PLACEHOLDER_KEY = "key"
+
+// KeyGenerator return key of given index substituted into {key} placeholder, return false when there is no more key
+type KeyGenerator func(index int) (string, bool)
+
+// Iterate over explicit list of keys (e.g. letters, region codes, account IDs)
+func KeysOf(keys ...string) KeyGenerator {
+	return func(index int) (string, bool) {
+
+		if index >= len(keys) {
+			return "", false
+		}
+
+		return keys[index], true
+	}
+}
+
+// Iterate over dates from (inclusive) to (exclusive) on every step formatted with layout
+func DateKeys(from, to time.Time, step time.Duration, layout string) KeyGenerator {
+	return func(index int) (string, bool) {
+
+		date := from.Add(time.Duration(index) * step)
+
+		if !date.Before(to) {
+			return "", false
+		}
+
+		return date.Format(layout), true
+	}
+}
+
+// fetch every key concurrently in batches instead of integer pointer range
+func (obj *PaginationAggregator) getByKeys() ([]HttpInteraction, error) {
+
+	index := 0
+
+	for {
+
+		var keys []string
+
+		for len(keys) < obj.concurrent {
+
+			key, ok := obj.keys(index)
+
+			if !ok {
+				break
+			}
+
+			keys = append(keys, key)
+			index++
+		}
+
+		if len(keys) == 0 {
+			break
+		}
+
+		if err := obj.collect(obj.fetchKeys(obj.start+index-len(keys), keys)); err != nil {
+			return obj.finish(err)
+		}
+
+		if obj.ctx != nil && obj.ctx.Err() != nil {
+			return nil, obj.ctx.Err()
+		}
+
+		if _, ok := obj.keys(index); ok {
+			time.Sleep(time.Duration(obj.delayBetweenBatch) * time.Second)
+		}
+	}
+
+	return obj.result, nil
+}
+
+func (obj *PaginationAggregator) fetchKeys(pointer int, keys []string) []HttpInteraction {
+
+	var values []map[string]string
+
+	for _, key := range keys {
+		values = append(values, map[string]string{PLACEHOLDER_KEY: key})
+	}
+
+	interactions := obj.fetchValues(pointer, values)
+
+	for idx := range interactions {
+		interactions[idx].Request.Key = keys[idx]
+	}
+
+	return interactions
+}
+
+// fetch pages concurrently, every page use its own named placeholder values on top of default values
+func (obj *PaginationAggregator) fetchValues(pointer int, values []map[string]string) []HttpInteraction {
+
+	var wg sync.WaitGroup
+
+	interactions := make([]HttpInteraction, len(values))
+
+	for idx := range values {
+
+		wg.Add(1)
+
+		go func(idx int) {
+
+			defer wg.Done()
+
+			requestCtx, cancel := context.WithTimeout(context.Background(), time.Duration(obj.timeout)*time.Second)
+			defer cancel()
+
+			pageValues := obj.templateValues(pointer+idx, "")
+
+			for key, value := range values[idx] {
+				pageValues[key] = value
+			}
+
+			req, err := obj.newRequestWithValues(requestCtx, pointer+idx, pageValues)
+
+			interactions[idx] = obj.roundTrip(req, err, pointer+idx)
+		}(idx)
+	}
+
+	wg.Wait()
+
+	return interactions
+}
@@ -0,0 +1,123 @@
+package paginationaggregator
+
+import (
+	"errors"
+	"sort"
+)
+
+// expand matrix dimensions into cartesian product of named placeholder values in deterministic order
+func expandMatrix(matrix map[string][]string) []map[string]string {
+
+	var dimensions []string
+
+	for dimension := range matrix {
+		dimensions = append(dimensions, dimension)
+	}
+
+	sort.Strings(dimensions)
+
+	combinations := []map[string]string{{}}
+
+	for _, dimension := range dimensions {
+
+		var expanded []map[string]string
+
+		for _, combination := range combinations {
+			for _, value := range matrix[dimension] {
+
+				next := map[string]string{dimension: value}
+
+				for key, val := range combination {
+					next[key] = val
+				}
+
+				expanded = append(expanded, next)
+			}
+		}
+
+		combinations = expanded
+	}
+
+	return combinations
+}
+
+// paginate every matrix combination with its own boundary discovery, tagging requests with its combination
+func (obj *PaginationAggregator) getByMatrix() ([]HttpInteraction, error) {
+
+	for _, combination := range expandMatrix(obj.matrix) {
+
+		child := obj.spawn(combination)
+
+		result, err := child.Get()
+
+		if !obj.discardResult {
+			obj.result = append(obj.result, result...)
+		}
+
+		if err != nil {
+			return obj.result, err
+		}
+
+		if obj.ctx != nil && obj.ctx.Err() != nil {
+			return nil, obj.ctx.Err()
+		}
+	}
+
+	return obj.result, nil
+}
+
+// create aggregator from same configurations with additional placeholder values,
+// its batches are tagged with those values and forwarded to parent callbacks
+func (obj *PaginationAggregator) spawn(params map[string]string) *PaginationAggregator {
+
+	child := obj.config.build()
+	child.ctx = obj.ctx
+	child.matrix = nil
+
+	// every combination decode boundary on its own json page
+	child.jsonPages = newJsonPage(obj.config.JsonPage)
+	child.params = map[string]string{}
+
+	for key, value := range obj.params {
+		child.params[key] = value
+	}
+
+	for key, value := range params {
+		child.params[key] = value
+	}
+
+	tag := func(batchResult []HttpInteraction) {
+		for _, val := range batchResult {
+			val.Request.Params = params
+		}
+	}
+
+	// combination share limiter and result hook of multi source or join which owns parent
+	child.limiter = obj.limiter
+	child.discardResult = obj.discardResult
+
+	if obj.batchHook != nil {
+		child.batchHook = func(batchResult []HttpInteraction) error {
+			tag(batchResult)
+			return obj.batchHook(batchResult)
+		}
+	}
+
+	child.concurrentBatch = func(batchResult []HttpInteraction) error {
+		tag(batchResult)
+		return obj.executeCallback(batchResult)
+	}
+
+	return child.fillDefault()
+}
+
+func validateMatrix(matrix map[string][]string) error {
+
+	for dimension, values := range matrix {
+		if len(values) == 0 {
+			return errors.New("No Values Found On Matrix Dimension " + dimension)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,252 @@
+package paginationaggregator
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+var errMsgPackShort = errors.New("MessagePack Data Too Short")
+
+// decode single MessagePack value, numbers become float64 same as decoded json
+func decodeMsgPack(data []byte) (interface{}, error) {
+
+	reader := &msgPackReader{data: data}
+
+	value, err := reader.value()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+type msgPackReader struct {
+	data   []byte
+	offset int
+}
+
+func (obj *msgPackReader) next(size int) ([]byte, error) {
+
+	if obj.offset+size > len(obj.data) {
+		return nil, errMsgPackShort
+	}
+
+	bytes := obj.data[obj.offset : obj.offset+size]
+	obj.offset += size
+
+	return bytes, nil
+}
+
+func (obj *msgPackReader) uint(size int) (uint64, error) {
+
+	bytes, err := obj.next(size)
+
+	if err != nil {
+		return 0, err
+	}
+
+	switch size {
+	case 1:
+		return uint64(bytes[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(bytes)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(bytes)), nil
+	default:
+		return binary.BigEndian.Uint64(bytes), nil
+	}
+}
+
+func (obj *msgPackReader) int(size int) (float64, error) {
+
+	value, err := obj.uint(size)
+
+	if err != nil {
+		return 0, err
+	}
+
+	switch size {
+	case 1:
+		return float64(int8(value)), nil
+	case 2:
+		return float64(int16(value)), nil
+	case 4:
+		return float64(int32(value)), nil
+	default:
+		return float64(int64(value)), nil
+	}
+}
+
+func (obj *msgPackReader) value() (interface{}, error) {
+
+	prefix, err := obj.uint(1)
+
+	if err != nil {
+		return nil, err
+	}
+
+	code := byte(prefix)
+
+	switch {
+	case code <= 0x7f:
+		return float64(code), nil
+	case code >= 0xe0:
+		return float64(int8(code)), nil
+	case code >= 0x80 && code <= 0x8f:
+		return obj.mapOf(int(code & 0x0f))
+	case code >= 0x90 && code <= 0x9f:
+		return obj.arrayOf(int(code & 0x0f))
+	case code >= 0xa0 && code <= 0xbf:
+		return obj.stringOf(int(code & 0x1f))
+	}
+
+	switch code {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xd9:
+		return obj.sizedString(1)
+	case 0xc5, 0xda:
+		return obj.sizedString(2)
+	case 0xc6, 0xdb:
+		return obj.sizedString(4)
+	case 0xca:
+		bits, err := obj.uint(4)
+		return float64(math.Float32frombits(uint32(bits))), err
+	case 0xcb:
+		bits, err := obj.uint(8)
+		return math.Float64frombits(bits), err
+	case 0xcc:
+		value, err := obj.uint(1)
+		return float64(value), err
+	case 0xcd:
+		value, err := obj.uint(2)
+		return float64(value), err
+	case 0xce:
+		value, err := obj.uint(4)
+		return float64(value), err
+	case 0xcf:
+		value, err := obj.uint(8)
+		return float64(value), err
+	case 0xd0:
+		return obj.int(1)
+	case 0xd1:
+		return obj.int(2)
+	case 0xd2:
+		return obj.int(4)
+	case 0xd3:
+		return obj.int(8)
+	case 0xdc:
+		return obj.sizedArray(2)
+	case 0xdd:
+		return obj.sizedArray(4)
+	case 0xde:
+		return obj.sizedMap(2)
+	case 0xdf:
+		return obj.sizedMap(4)
+	}
+
+	return nil, fmt.Errorf("Unsupported MessagePack Type 0x%x", code)
+}
+
+func (obj *msgPackReader) sizedString(size int) (interface{}, error) {
+
+	length, err := obj.uint(size)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return obj.stringOf(int(length))
+}
+
+func (obj *msgPackReader) stringOf(length int) (interface{}, error) {
+
+	bytes, err := obj.next(length)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return string(bytes), nil
+}
+
+func (obj *msgPackReader) sizedArray(size int) (interface{}, error) {
+
+	length, err := obj.uint(size)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return obj.arrayOf(int(length))
+}
+
+func (obj *msgPackReader) arrayOf(length int) (interface{}, error) {
+
+	// every element takes at least one byte, impossible length is rejected before allocation
+	if length > len(obj.data)-obj.offset {
+		return nil, errMsgPackShort
+	}
+
+	items := make([]interface{}, 0, length)
+
+	for i := 0; i < length; i++ {
+
+		item, err := obj.value()
+
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+func (obj *msgPackReader) sizedMap(size int) (interface{}, error) {
+
+	length, err := obj.uint(size)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return obj.mapOf(int(length))
+}
+
+func (obj *msgPackReader) mapOf(length int) (interface{}, error) {
+
+	// every key and value take at least one byte each
+	if length > (len(obj.data)-obj.offset)/2 {
+		return nil, errMsgPackShort
+	}
+
+	fields := make(map[string]interface{}, length)
+
+	for i := 0; i < length; i++ {
+
+		key, err := obj.value()
+
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := obj.value()
+
+		if err != nil {
+			return nil, err
+		}
+
+		fields[fmt.Sprint(key)] = value
+	}
+
+	return fields, nil
+}
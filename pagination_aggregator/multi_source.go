@@ -0,0 +1,196 @@
+package paginationaggregator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Source is single named configuration of multi source aggregation
+type Source struct {
+	Name   string
+	Config *PaginationAggregatorConfig
+}
+
+type MultiSourceConfig struct {
+	// Sources of single logical dataset (e.g. regions)
+	Sources []Source
+
+	// Number of concurrent requests shared by all sources
+	Concurrent int
+
+	//	Override this function to manage behaviour for every batch requests of any source
+	ConcurrentBatch BatchCallback
+
+	//	Override this function to manage behaviour for every batch requests of any source with injected context
+	ConcurrentBatchWithContext BatchCallbackWithContext
+}
+
+// SourceError is error which stop aggregation of single source
+type SourceError struct {
+	Source string
+	Err    error
+}
+
+// ErrorReport combine stopped sources and failed pages of every source
+type ErrorReport struct {
+	Sources []SourceError
+	Pages   []HttpInteraction
+}
+
+func (obj *ErrorReport) Error() string {
+
+	var messages []string
+
+	for _, val := range obj.Sources {
+		messages = append(messages, fmt.Sprintf("%s: %s", val.Source, val.Err.Error()))
+	}
+
+	return fmt.Sprintf("%d sources failed (%s), %d pages failed", len(obj.Sources), strings.Join(messages, "; "), len(obj.Pages))
+}
+
+// MultiSourceAggregator run several configurations as one job and merge them into one result
+type MultiSourceAggregator struct {
+	ctx                        context.Context
+	names                      []string
+	aggregators                []*PaginationAggregator
+	concurrentBatch            BatchCallback
+	concurrentBatchWithContext BatchCallbackWithContext
+	mutex                      sync.Mutex
+	result                     []HttpInteraction
+	report                     ErrorReport
+}
+
+func NewMultiSourceAggregator(config *MultiSourceConfig) (*MultiSourceAggregator, error) {
+	return newMultiSourceAggregator(nil, config)
+}
+
+func NewMultiSourceAggregatorWithContext(ctx context.Context, config *MultiSourceConfig) (*MultiSourceAggregator, error) {
+	return newMultiSourceAggregator(ctx, config)
+}
+
+func newMultiSourceAggregator(ctx context.Context, config *MultiSourceConfig) (*MultiSourceAggregator, error) {
+
+	if len(config.Sources) == 0 {
+		return nil, errors.New("No Sources Found")
+	}
+
+	concurrent := config.Concurrent
+
+	if concurrent == 0 {
+		concurrent = DEFAULT_CONCURRENT
+	}
+
+	multi := &MultiSourceAggregator{
+		ctx:                        ctx,
+		concurrentBatch:            config.ConcurrentBatch,
+		concurrentBatchWithContext: config.ConcurrentBatchWithContext,
+	}
+
+	limiter := make(chan struct{}, concurrent)
+	names := map[string]bool{}
+
+	for _, source := range config.Sources {
+
+		var pag *PaginationAggregator
+		var err error
+
+		if names[source.Name] {
+			return nil, errors.New("Duplicate Source Name " + source.Name)
+		}
+
+		names[source.Name] = true
+
+		if ctx != nil {
+			pag, err = NewPaginationAggregatorWithContext(ctx, source.Config)
+		} else {
+			pag, err = NewPaginationAggregator(source.Config)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("Source %s: %w", source.Name, err)
+		}
+
+		pag.limiter = limiter
+		pag.discardResult = true
+		pag.batchHook = multi.hook(source.Name)
+
+		multi.names = append(multi.names, source.Name)
+		multi.aggregators = append(multi.aggregators, pag)
+	}
+
+	return multi, nil
+}
+
+// Get run every source concurrently, returned error is *ErrorReport when any source stopped with error
+func (obj *MultiSourceAggregator) Get() ([]HttpInteraction, error) {
+
+	var wg sync.WaitGroup
+
+	// reset state of previous run, so aggregator can be run again
+	obj.result = nil
+	obj.report = ErrorReport{}
+
+	for idx, pag := range obj.aggregators {
+
+		wg.Add(1)
+
+		go func(name string, pag *PaginationAggregator) {
+
+			defer wg.Done()
+
+			if _, err := pag.Get(); err != nil {
+
+				obj.mutex.Lock()
+				defer obj.mutex.Unlock()
+
+				obj.report.Sources = append(obj.report.Sources, SourceError{Source: name, Err: err})
+			}
+		}(obj.names[idx], pag)
+	}
+
+	wg.Wait()
+
+	if len(obj.report.Sources) > 0 {
+		return obj.result, &obj.report
+	}
+
+	return obj.result, nil
+}
+
+// Report return stopped sources and failed pages of every source
+func (obj *MultiSourceAggregator) Report() ErrorReport {
+	return obj.report
+}
+
+// tag batch of a source and forward it into combined result and callbacks
+func (obj *MultiSourceAggregator) hook(name string) BatchCallback {
+	return func(batchResult []HttpInteraction) error {
+
+		obj.mutex.Lock()
+		defer obj.mutex.Unlock()
+
+		for _, val := range batchResult {
+
+			val.Request.Source = name
+
+			if val.Response.Error != nil {
+				obj.report.Pages = append(obj.report.Pages, val)
+			}
+		}
+
+		obj.result = append(obj.result, batchResult...)
+
+		if obj.concurrentBatch != nil {
+			return obj.concurrentBatch(batchResult)
+		}
+
+		if obj.concurrentBatchWithContext != nil {
+			return obj.concurrentBatchWithContext(obj.ctx, batchResult)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,246 @@
+package paginationaggregator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Nested spawn child paginated aggregation for every item of parent pages (e.g. issues of every repository)
+type Nested struct {
+
+	// Name recorded on parent link of every child interaction
+	Name string
+
+	// Configuration of child aggregation, its named placeholders are filled with parent item fields (e.g. {id}, {owner.login}).
+	// Child aggregation share parent client and concurrency budget
+	Config *PaginationAggregatorConfig
+}
+
+// ParentItem link child interaction back to the item which spawned it
+type ParentItem struct {
+	Name        string
+	Item        interface{}
+	Interaction *HttpInteraction
+}
+
+// fetch child aggregations of every item in batch concurrently and return its interactions
+func (obj *PaginationAggregator) collectNested(tmpBatch []HttpInteraction) []HttpInteraction {
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	var children []HttpInteraction
+
+	// page requests of children hold limiter, so running children are bounded by their own slots
+	slots := make(chan struct{}, obj.concurrent)
+
+	for idx := range tmpBatch {
+
+		parent := &tmpBatch[idx]
+		exact := obj.exactItems(parent.Response)
+
+		for i, item := range parent.Response.Items {
+			for _, nested := range obj.nested {
+
+				wg.Add(1)
+				slots <- struct{}{}
+
+				go func(nested Nested, item interface{}, exact interface{}) {
+
+					defer wg.Done()
+					defer func() { <-slots }()
+
+					result := obj.runNested(nested, itemFields(item, exact))
+
+					for i := range result {
+						result[i].Parent = &ParentItem{Name: nested.Name, Item: item, Interaction: parent}
+					}
+
+					mutex.Lock()
+					children = append(children, result...)
+					mutex.Unlock()
+				}(nested, item, exact[i])
+			}
+		}
+	}
+
+	wg.Wait()
+
+	return children
+}
+
+func (obj *PaginationAggregator) runNested(nested Nested, fields map[string]string) []HttpInteraction {
+
+	config := *nested.Config
+	config.Params = map[string]string{}
+
+	// item fields named as reserved placeholders (e.g. limit) are not substituted
+	for key, value := range fields {
+		if !reservedPlaceholders[key] {
+			config.Params[key] = value
+		}
+	}
+
+	for key, value := range nested.Config.Params {
+		config.Params[key] = value
+	}
+
+	if config.Client == nil {
+		config.Client = obj.client
+	}
+
+	// every child decode boundary on its own json page
+	config.JsonPage = newJsonPage(config.JsonPage)
+
+	var child *PaginationAggregator
+	var err error
+
+	if obj.ctx != nil {
+		child, err = NewPaginationAggregatorWithContext(obj.ctx, &config)
+	} else {
+		child, err = NewPaginationAggregator(&config)
+	}
+
+	if err != nil {
+		return []HttpInteraction{obj.nestedFailure(&config, err)}
+	}
+
+	child.limiter = obj.limiter
+
+	result, err := child.Get()
+
+	if err != nil {
+		result = append(result, obj.nestedFailure(&config, err))
+	}
+
+	return result
+}
+
+// failure of child aggregation itself, its request is first page of child
+func (obj *PaginationAggregator) nestedFailure(config *PaginationAggregatorConfig, err error) HttpInteraction {
+
+	start := config.Start
+
+	if start == 0 {
+		start = DEFAULT_START
+	}
+
+	method := strings.ToUpper(config.Method)
+
+	if method == "" {
+		method = DEFAULT_METHOD
+	}
+
+	values := map[string]string{
+		PLACEHOLDER_PAGE:    strconv.Itoa(start),
+		PLACEHOLDER_POINTER: strconv.Itoa(start),
+	}
+
+	for key, value := range config.Params {
+		values[key] = value
+	}
+
+	req, _ := http.NewRequest(method, newURLTemplate(config.URL).render(start, values), nil)
+
+	return obj.failure(req, start, http.StatusInternalServerError, err, nil)
+}
+
+// items of page decoded with json numbers, so big integer fields are substituted exactly
+func (obj *PaginationAggregator) exactItems(response *Response) []interface{} {
+
+	if tree, err := response.decodeExact(); err == nil {
+		if exact, ok := response.lookupItems(tree, obj.itemsPath); ok && len(exact) == len(response.Items) {
+			return exact
+		}
+	}
+
+	return response.Items
+}
+
+func newJsonPage(jsonPage JsonMetaPages) JsonMetaPages {
+
+	if jsonPage == nil {
+		return nil
+	}
+
+	value := reflect.ValueOf(jsonPage)
+
+	if value.Kind() != reflect.Ptr {
+		return jsonPage
+	}
+
+	return reflect.New(value.Type().Elem()).Interface().(JsonMetaPages)
+}
+
+// placeholder values of item, numbers are taken from exact item when they are same value so big integers are substituted exactly.
+// Fields added to item after it is decoded (e.g. enrichment) are kept
+func itemFields(item interface{}, exact interface{}) map[string]string {
+
+	fields := flattenItem(item)
+
+	for key, value := range flattenItem(exact) {
+		if number, err := strconv.ParseFloat(value, 64); err == nil && strconv.FormatFloat(number, 'f', -1, 64) == fields[key] {
+			fields[key] = value
+		}
+	}
+
+	return fields
+}
+
+// flatten item fields into dot separated placeholder values (e.g. owner.login)
+func flattenItem(item interface{}) map[string]string {
+
+	values := map[string]string{}
+
+	var flatten func(prefix string, value interface{})
+
+	flatten = func(prefix string, value interface{}) {
+		switch node := value.(type) {
+		case map[string]interface{}:
+			for key, val := range node {
+
+				if prefix != "" {
+					key = prefix + "." + key
+				}
+
+				flatten(key, val)
+			}
+		case []interface{}:
+			for idx, val := range node {
+				flatten(prefix+"."+strconv.Itoa(idx), val)
+			}
+		case json.Number:
+			values[prefix] = node.String()
+		case float64:
+			values[prefix] = strconv.FormatFloat(node, 'f', -1, 64)
+		case nil:
+			values[prefix] = ""
+		default:
+			values[prefix] = fmt.Sprint(node)
+		}
+	}
+
+	flatten("", item)
+
+	return values
+}
+
+func validateNested(nested []Nested, itemsPath string) error {
+
+	if len(nested) > 0 && itemsPath == "" {
+		return errors.New("No Items Path Found For Nested Aggregation")
+	}
+
+	for _, val := range nested {
+		if val.Config == nil {
+			return errors.New("No Configurations Found For Nested Aggregation " + val.Name)
+		}
+	}
+
+	return nil
+}
@@ -2,8 +2,9 @@ package paginationaggregator
 
 import (
 	"context"
+	"encoding/json"
+	"encoding/xml"
 	"errors"
-	"fmt"
 	"io"
 	"net/http"
 	"sync"
@@ -15,6 +16,9 @@ const (
 	DEFAULT_TIMEOUT    = 3
 	DEFAULT_DELAY      = 2
 	DEFAULT_START      = 1
+	DEFAULT_METHOD     = http.MethodGet
+
+	IDEMPOTENCY_KEY_HEADER = "Idempotency-Key"
 )
 
 type Header map[string]string
@@ -25,18 +29,30 @@ type BatchCallbackWithContext func(ctx context.Context, batchResult []HttpIntera
 
 type Pointer func(current *int, boundary int)
 
+type RequestBuilder func(ctx context.Context, pointer int) (*http.Request, error)
+
+type IdempotencyKey func(pointer int) string
+
+type BoundaryChange func(previous int, current int)
+
 type JsonMetaPages interface {
 	GetBoundary() int
 }
 
-type preProcessingAggregator interface {
-	accept(pag *PaginationAggregator) error
+type HeaderMetaPages interface {
+	GetBoundaryFromHeader(header http.Header) int
 }
 
 type PaginationAggregator struct {
 	client                     *http.Client
-	url                        string
+	template                   *urlTemplate
+	limit                      int
+	params                     map[string]string
 	headers                    Header
+	requestBuilder             RequestBuilder
+	method                     string
+	body                       *urlTemplate
+	idempotencyKey             IdempotencyKey
 	ctx                        context.Context
 	start                      int
 	boundary                   int
@@ -48,16 +64,98 @@ type PaginationAggregator struct {
 	concurrentBatchWithContext BatchCallbackWithContext
 	pointer                    Pointer
 	jsonPages                  JsonMetaPages
-	visitor                    []preProcessingAggregator
+	headerPages                HeaderMetaPages
+	reevaluateBoundary         bool
+	boundaryChange             BoundaryChange
+	terminators                []Terminator
+	runTerminators             []Terminator
+	cursor                     CursorStrategy
+	transport                  Transport
+	jsonRPC                    *JsonRPC
+	jsonRPCParams              *urlTemplate
+	session                    *Session
+	tokenExpiry                *TokenExpiry
+	expiryState                *tokenExpiryState
+	sessionCursor              string
+	rangePages                 *RangePagination
+	timeWindow                 *TimeWindow
+	keys                       KeyGenerator
+	matrix                     map[string][]string
+	config                     *PaginationAggregatorConfig
+	itemsPath                  string
+	nested                     []Nested
+	enrichment                 *Enrichment
+	enrichmentURL              *urlTemplate
+	limiter                    chan struct{}
+	batchHook                  BatchCallback
+	discardResult              bool
+	decoder                    Decoder
+	partitions                 []Partition
+	partitionProgress          PartitionProgressCallback
+	mutex                      sync.Mutex
+	plugins                    []Plugin
+	state                      *State
 }
 
 func (obj *PaginationAggregator) Get() ([]HttpInteraction, error) {
 
+	obj.startRun()
+
+	// every combination runs plugins on its own child aggregation
+	if len(obj.matrix) > 0 {
+		return obj.getByMatrix()
+	}
+
+	if err := obj.beforeRun(); err != nil {
+		return obj.afterRun(nil, err)
+	}
+
+	return obj.afterRun(obj.get())
+}
+
+// reset state of previous run, so aggregator can be run again
+func (obj *PaginationAggregator) startRun() {
+
+	obj.result = nil
+	obj.runTerminators = newRunTerminators(obj.terminators)
+
+	// boundary discovered on previous run is discovered again
+	obj.mutex.Lock()
+	obj.boundary = obj.config.Boundary
+	obj.mutex.Unlock()
+
+	if obj.expiryState != nil {
+		*obj.expiryState = tokenExpiryState{}
+	}
+}
+
+func (obj *PaginationAggregator) get() ([]HttpInteraction, error) {
+
 	var err error
 	var wg sync.WaitGroup
 
-	if err = obj.runVisitor(); err != nil {
-		return nil, err
+	if len(obj.partitions) > 0 {
+		return obj.getByPartition()
+	}
+
+	if obj.session != nil {
+		return obj.getBySession()
+	}
+
+	if obj.jsonRPC != nil {
+		return obj.getByJsonRPC()
+	}
+
+	if obj.cursor != nil {
+		return obj.getByCursor()
+	}
+
+	if obj.timeWindow != nil {
+		return obj.getByTimeWindow()
+	}
+
+	if obj.keys != nil {
+		return obj.getByKeys()
 	}
 
 	channel := make(chan HttpInteraction, obj.concurrent)
@@ -68,20 +166,20 @@ func (obj *PaginationAggregator) Get() ([]HttpInteraction, error) {
 
 		currentPointer := pointer
 
-		wg.Add(1)
-
 		obj.executePointer(&currentPointer, obj.boundary)
 
-		go obj.fetch(&batch, currentPointer, channel, &wg)
+		if currentPointer > obj.boundary {
+			break
+		}
+
+		wg.Add(1)
+
+		go obj.fetch(currentPointer, channel, &wg)
 
 		batch++
 
 		if err = obj.processBatch(&batch, &currentPointer, channel, &wg); err != nil {
-			return obj.result, err
-		}
-
-		if currentPointer > obj.boundary {
-			break
+			return obj.finish(err)
 		}
 
 		if obj.ctx != nil && obj.ctx.Err() != nil {
@@ -89,137 +187,192 @@ func (obj *PaginationAggregator) Get() ([]HttpInteraction, error) {
 		}
 	}
 
-	return obj.result, nil
-}
+	if batch > 0 {
 
-func (obj *PaginationAggregator) fetch(batch *int, page int, channel chan<- HttpInteraction, wg *sync.WaitGroup) error {
+		// pointer overlap or boundary shrink may leave unprocessed batch
+		lastPointer := obj.boundary
 
-	if page > obj.boundary {
+		if err = obj.processBatch(&batch, &lastPointer, channel, &wg); err != nil {
+			return obj.finish(err)
+		}
+	}
 
-		wg.Done()
+	return obj.result, nil
+}
 
-		*batch--
+func (obj *PaginationAggregator) finish(err error) ([]HttpInteraction, error) {
 
-		return nil
+	if errors.Is(err, errTerminated) {
+		return obj.result, nil
 	}
 
-	var data []byte
+	return obj.result, err
+}
+
+func (obj *PaginationAggregator) fetch(page int, channel chan<- HttpInteraction, wg *sync.WaitGroup) {
+
+	defer wg.Done()
 
 	requestCtx, cancel := context.WithTimeout(context.Background(), time.Duration(obj.timeout)*time.Second)
 	defer cancel()
 
-	req, err := http.NewRequestWithContext(requestCtx, "GET", fmt.Sprintf(obj.url, page), nil)
-
-	if err != nil {
+	req, err := obj.newRequest(requestCtx, page, "")
 
-		channel <- HttpInteraction{
-			Request: &Request{
-				HttpRequest: req,
-				Pointer:     page,
-			},
-			Response: &Response{
-				Status:     http.StatusInternalServerError,
-				StatusText: http.StatusText(http.StatusInternalServerError),
-				Error:      err,
-				Data:       "",
-			},
-		}
+	channel <- obj.roundTrip(req, err, page)
+}
 
-		wg.Done()
+// send page request through plugin hooks, request build error is recorded as failure of that page
+func (obj *PaginationAggregator) roundTrip(req *http.Request, err error, page int) HttpInteraction {
 
-		return err
+	if err == nil {
+		err = obj.beforeRequest(req)
 	}
 
-	for key, value := range obj.headers {
-		req.Header.Set(key, value)
-	}
+	interaction := obj.exchange(req, err, page)
+
+	obj.afterResponse(&interaction)
+
+	return interaction
+}
+
+// send page request and wrap its response
+func (obj *PaginationAggregator) exchange(req *http.Request, err error, page int) HttpInteraction {
 
-	resp, err := obj.client.Do(req)
+	var data []byte
 
 	if err != nil {
-		channel <- HttpInteraction{
-			Request: &Request{
-				HttpRequest: req,
-				Pointer:     page,
-			},
-			Response: &Response{
-				Status:     http.StatusInternalServerError,
-				StatusText: http.StatusText(http.StatusInternalServerError),
-				Error:      err,
-				Data:       "",
-			},
-		}
+		return obj.failure(req, page, http.StatusInternalServerError, err, nil)
+	}
 
-		wg.Done()
+	resp, err := obj.do(req)
 
-		return err
+	if err != nil {
+		return obj.failure(req, page, http.StatusInternalServerError, err, nil)
 	}
 
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 && resp.StatusCode <= 599 {
 
-		var err error
-
-		data, err = io.ReadAll(resp.Body)
-
-		if err == nil && data != nil {
-			err = errors.New(string(data))
-		} else {
-			err = errors.New(http.StatusText(resp.StatusCode))
-		}
+		interaction := obj.failure(req, page, resp.StatusCode, errors.New(http.StatusText(resp.StatusCode)), resp.Header)
 
-		channel <- HttpInteraction{
-			Request: &Request{
-				HttpRequest: req,
-				Pointer:     page,
-			},
-			Response: &Response{
-				Status:     resp.StatusCode,
-				StatusText: http.StatusText(resp.StatusCode),
-				Error:      errors.New(http.StatusText(resp.StatusCode)),
-				Data:       "",
-			},
+		// expired token predicate may inspect error body
+		if obj.tokenExpiry != nil {
+			data, _ = io.ReadAll(resp.Body)
+			interaction.Response.Data = string(data)
 		}
 
-		wg.Done()
-		return err
+		return interaction
 	}
 
 	if data, err = io.ReadAll(resp.Body); err != nil {
-		channel <- HttpInteraction{
-			Request: &Request{
-				HttpRequest: req,
-				Pointer:     page,
-			},
-			Response: &Response{
-				Status:     http.StatusInternalServerError,
-				StatusText: http.StatusText(resp.StatusCode),
-				Error:      err,
-				Data:       "",
-			},
-		}
+		return obj.failure(req, page, http.StatusInternalServerError, err, resp.Header)
+	}
 
-		wg.Done()
+	response := &Response{
+		Status:     resp.StatusCode,
+		StatusText: resp.Status,
+		Data:       string(data),
+		Header:     resp.Header,
+		decoder:    obj.decoder,
+	}
 
-		return err
+	response.Items = obj.extractItems(response)
+
+	return HttpInteraction{
+		Request: &Request{
+			HttpRequest: req,
+			Pointer:     page,
+		},
+		Response: response,
+	}
+}
+
+// send request within shared concurrency budget of nested aggregations and enrichments
+func (obj *PaginationAggregator) do(req *http.Request) (*http.Response, error) {
+
+	if obj.limiter != nil {
+		obj.limiter <- struct{}{}
+		defer func() { <-obj.limiter }()
 	}
 
-	channel <- HttpInteraction{
+	return obj.client.Do(req)
+}
+
+func (obj *PaginationAggregator) extractItems(response *Response) []interface{} {
+
+	if obj.itemsPath == "" {
+		return nil
+	}
+
+	tree, err := response.Decode()
+
+	if err != nil {
+		return nil
+	}
+
+	items, _ := response.lookupItems(tree, obj.itemsPath)
+
+	return items
+}
+
+func (obj *PaginationAggregator) failure(req *http.Request, page int, status int, err error, header http.Header) HttpInteraction {
+	return HttpInteraction{
 		Request: &Request{
 			HttpRequest: req,
 			Pointer:     page,
 		},
 		Response: &Response{
-			Status:     resp.StatusCode,
-			StatusText: resp.Status,
-			Data:       string(data),
+			Status:     status,
+			StatusText: http.StatusText(status),
+			Error:      err,
+			Data:       "",
+			Header:     header,
 		},
 	}
+}
 
-	wg.Done()
+func (obj *PaginationAggregator) newRequest(ctx context.Context, pointer int, cursor string) (*http.Request, error) {
+	return obj.newRequestWithValues(ctx, pointer, obj.templateValues(pointer, cursor))
+}
 
-	return nil
+// build page request with given named placeholder values
+func (obj *PaginationAggregator) newRequestWithValues(ctx context.Context, pointer int, values map[string]string) (*http.Request, error) {
+
+	var req *http.Request
+	var err error
+
+	if obj.requestBuilder != nil {
+		req, err = obj.requestBuilder(ctx, pointer)
+	} else {
+		// request body built from strings reader is replayable through req.GetBody on retries and redirects
+		req, err = http.NewRequestWithContext(ctx, obj.method, obj.buildURL(pointer, values), obj.buildBody(pointer, values))
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if obj.requestBuilder == nil {
+
+		if obj.body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		for key, value := range obj.headers {
+			req.Header.Set(key, value)
+		}
+	}
+
+	if obj.rangePages != nil {
+		req.Header.Set("Range", obj.rangePages.header(pointer))
+	}
+
+	if obj.idempotencyKey != nil && req.Header.Get(IDEMPOTENCY_KEY_HEADER) == "" {
+		req.Header.Set(IDEMPOTENCY_KEY_HEADER, obj.idempotencyKey(pointer))
+	}
+
+	return req, nil
 }
 
 func (obj *PaginationAggregator) processBatch(batch, currentPointer *int, channel <-chan HttpInteraction, wg *sync.WaitGroup) error {
@@ -234,9 +387,7 @@ func (obj *PaginationAggregator) processBatch(batch, currentPointer *int, channe
 			tmpBatch = append(tmpBatch, <-channel)
 		}
 
-		obj.result = append(obj.result, tmpBatch...)
-
-		if err := obj.executeCallback(tmpBatch); err != nil {
+		if err := obj.collect(tmpBatch); err != nil {
 			return err
 		}
 
@@ -251,6 +402,125 @@ func (obj *PaginationAggregator) processBatch(batch, currentPointer *int, channe
 
 }
 
+// collect finished batch into result and run batch callback
+func (obj *PaginationAggregator) collect(tmpBatch []HttpInteraction) error {
+
+	terminated := obj.terminate(&tmpBatch)
+
+	if obj.enrichment != nil {
+		obj.enrichItems(tmpBatch)
+	}
+
+	if len(obj.nested) > 0 {
+		tmpBatch = append(tmpBatch, obj.collectNested(tmpBatch)...)
+	}
+
+	if !obj.discardResult {
+		obj.result = append(obj.result, tmpBatch...)
+	}
+
+	if obj.batchHook != nil {
+		if err := obj.batchHook(tmpBatch); err != nil {
+			return err
+		}
+	}
+
+	if obj.reevaluateBoundary {
+		obj.evaluateBoundary(tmpBatch)
+	}
+
+	if err := obj.executeCallback(tmpBatch); err != nil {
+		return err
+	}
+
+	if err := obj.afterBatch(tmpBatch); err != nil {
+		return err
+	}
+
+	if terminated {
+		return errTerminated
+	}
+
+	return nil
+}
+
+func (obj *PaginationAggregator) evaluateBoundary(tmpBatch []HttpInteraction) {
+
+	var latest *HttpInteraction
+
+	// most recent page holds the freshest boundary, pages of nested children have their own boundary
+	for idx := range tmpBatch {
+
+		if tmpBatch[idx].Response.Error != nil || tmpBatch[idx].Parent != nil {
+			continue
+		}
+
+		if latest == nil || tmpBatch[idx].Request.Pointer > latest.Request.Pointer {
+			latest = &tmpBatch[idx]
+		}
+	}
+
+	if latest == nil {
+		return
+	}
+
+	boundary, err := obj.readBoundary(latest.Response.Header, []byte(latest.Response.Data))
+
+	if err != nil || boundary <= 0 || boundary == obj.boundary {
+		return
+	}
+
+	if obj.boundaryChange != nil {
+		obj.boundaryChange(obj.boundary, boundary)
+	}
+
+	// plugins read boundary from concurrent page requests
+	obj.mutex.Lock()
+	obj.boundary = boundary
+	obj.mutex.Unlock()
+}
+
+func (obj *PaginationAggregator) readBoundary(header http.Header, data []byte) (int, error) {
+
+	if obj.headerPages != nil {
+		return obj.headerPages.GetBoundaryFromHeader(header), nil
+	}
+
+	decoder := obj.decoder
+
+	if decoder == nil {
+		decoder = decoderFor(header.Get("Content-Type"))
+	}
+
+	switch decoder.(type) {
+	case jsonDecoder:
+		if err := json.Unmarshal(data, &obj.jsonPages); err != nil {
+			return 0, err
+		}
+	case xmlDecoder:
+		if err := xml.Unmarshal(data, obj.jsonPages); err != nil {
+			return 0, err
+		}
+	default:
+		// other response is bound to json page through its decoded tree
+		tree, err := decoder.Decode(data)
+
+		if err != nil {
+			return 0, err
+		}
+
+		if data, err = json.Marshal(tree); err != nil {
+			return 0, err
+		}
+
+		if err := json.Unmarshal(data, &obj.jsonPages); err != nil {
+			return 0, err
+		}
+	}
+
+	return obj.jsonPages.GetBoundary(), nil
+}
+
 func (obj *PaginationAggregator) executePointer(currentPointer *int, boundary int) {
 
 	if obj.pointer != nil {
@@ -276,18 +546,20 @@ func (obj *PaginationAggregator) fillDefault() *PaginationAggregator {
 		obj.delayBetweenBatch = DEFAULT_DELAY
 	}
 
-	return obj
-}
+	if obj.method == "" {
+		obj.method = DEFAULT_METHOD
+	}
 
-func (obj *PaginationAggregator) runVisitor() error {
+	if (len(obj.nested) > 0 || obj.enrichment != nil) && obj.limiter == nil {
+		obj.limiter = make(chan struct{}, obj.concurrent)
+	}
 
-	for _, visit := range obj.visitor {
-		if err := visit.accept(obj); err != nil {
-			return err
-		}
+	if obj.tokenExpiry != nil && obj.expiryState == nil {
+		obj.expiryState = &tokenExpiryState{}
+		obj.cursor = &expiryCursor{CursorStrategy: obj.cursor, expiry: obj.tokenExpiry, state: obj.expiryState}
 	}
 
-	return nil
+	return obj
 }
 
 func (obj *PaginationAggregator) executeCallback(tmpBatch []HttpInteraction) error {
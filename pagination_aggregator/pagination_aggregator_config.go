@@ -4,18 +4,37 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"strings"
 )
 
 type PaginationAggregatorConfig struct {
 	// http client
 	Client *http.Client
 
-	// API url with integer placeholder (%d)
+	// API url with integer placeholder (%d) or named placeholders ({page}, {pointer}, {offset}, {limit} and Params keys)
 	URL string
 
+	// Page size substituted into {limit} and used to compute {offset} placeholders
+	Limit int
+
+	// Static values substituted into named URL placeholders
+	Params map[string]string
+
 	// Requests header
 	Headers Header
 
+	// Http method of every page request, default GET
+	Method string
+
+	// Json request body with named placeholders, same as URL placeholders (e.g. {"from": {offset}, "size": {limit}})
+	Body string
+
+	// Override this function to send unique idempotency key header on every page request
+	IdempotencyKey IdempotencyKey
+
+	// Override this function to build every page request by your own instead of URL and Headers
+	RequestBuilder RequestBuilder
+
 	// Start page/offset
 	Start int
 
@@ -40,10 +59,92 @@ type PaginationAggregatorConfig struct {
 	// Override this function to manage behaviour every pointer iteration
 	Pointer Pointer
 
-	// Struct which bind single json response to retrieve pagination boundary
+	// Cursor strategy to paginate sequentially where next request depends on previous response (e.g. GraphQL),
+	// Boundary becomes optional max pages
+	Cursor CursorStrategy
+
+	// Fetch pages through non http transport (e.g. gRPC List RPC) following next page token instead of Client and URL,
+	// Boundary becomes optional max pages
+	Transport Transport
+
+	// Start asynchronous export job, poll it until ready and fetch its chunk URLs instead of URL pages
+	ExportJob *ExportJob
+
+	// Open cursor resource before pagination, keep it alive and close it when pagination ends
+	Session *Session
+
+	// Send every page as JSON-RPC 2.0 request envelope, optionally several pages in one batch array,
+	// Boundary becomes optional max pages
+	JsonRPC *JsonRPC
+
+	// Recover cursor listing when continuation token expires (e.g. 410 Gone) instead of recording failed page
+	TokenExpiry *TokenExpiry
+
+	// Paginate with Range request header and Content-Range response header
+	Range *RangePagination
+
+	// Run independent cursor chain per partition concurrently, Concurrent limit number of running chains
+	Partitions []Partition
+
+	// Override this function to track fetched pages and checkpoint cursor of every partition
+	OnPartitionProgress PartitionProgressCallback
+
+	// Walk date range in time windows instead of integer pointer
+	TimeWindow *TimeWindow
+
+	// Dot separated path of items array in every response, extracted items are stored on Response.Items. Use "." when response is an array
+	ItemsPath string
+
+	// Spawn child aggregations for every item of fetched pages
+	Nested []Nested
+
+	// Fetch detail of every extracted item and merge it into the item
+	Enrichment *Enrichment
+
+	// Paginate every combination of dimension values substituted into named placeholders (e.g. {project} x {status})
+	Matrix map[string][]string
+
+	// Iterate over explicit keys substituted into {key} placeholder instead of integer pointer
+	Keys []string
+
+	// Override this function to generate keys substituted into {key} placeholder (e.g. DateKeys)
+	KeyGenerator KeyGenerator
+
+	// Decoder of every response, default decoder is chosen by response Content-Type (json, xml, csv, ndjson, msgpack)
+	Decoder Decoder
+
+	// Struct which bind single json response to retrieve pagination boundary, xml response is bound with xml tags
 	JsonPage JsonMetaPages
 
-	visitor []preProcessingAggregator
+	// Retrieve pagination boundary from response headers instead of json response
+	HeaderPage HeaderMetaPages
+
+	// Re-read boundary from every fetched page and extend or shrink remaining pointer range
+	ReevaluateBoundary bool
+
+	// Override this function to get notified whenever boundary changes during aggregation
+	OnBoundaryChange BoundaryChange
+
+	// Stop aggregation when one of terminators is satisfied on fetched page
+	Terminators []Terminator
+
+	// Hook into lifecycle of aggregation (before run, before request, after response, after batch, after run)
+	Plugins []Plugin
+
+	plugins []Plugin
+
+	template      *urlTemplate
+	body          *urlTemplate
+	jsonRPCParams *urlTemplate
+	enrichmentURL *urlTemplate
+
+	// derived configurations are kept apart from configurations of user, so same config can build several aggregators
+	cursor      CursorStrategy
+	keys        KeyGenerator
+	headerPage  HeaderMetaPages
+	terminators []Terminator
+	itemsPath   string
+	decoder     Decoder
 }
 
 func NewPaginationAggregator(config *PaginationAggregatorConfig) (*PaginationAggregator, error) {
@@ -52,20 +153,8 @@ func NewPaginationAggregator(config *PaginationAggregatorConfig) (*PaginationAgg
 		return nil, err
 	}
 
-	pag := PaginationAggregator{
-		client:            config.Client,
-		url:               config.URL,
-		start:             config.Start,
-		boundary:          config.Boundary,
-		headers:           config.Headers,
-		delayBetweenBatch: config.DelayBetweenBatch,
-		concurrent:        config.Concurrent,
-		timeout:           config.Timeout,
-		concurrentBatch:   config.ConcurrentBatch,
-		pointer:           config.Pointer,
-		jsonPages:         config.JsonPage,
-		visitor:           config.visitor,
-	}
+	pag := config.build()
+	pag.concurrentBatch = config.ConcurrentBatch
 
 	return pag.fillDefault(), nil
 }
@@ -76,38 +165,299 @@ func NewPaginationAggregatorWithContext(ctx context.Context, config *PaginationA
 		return nil, err
 	}
 
-	pag := PaginationAggregator{
-		client:                     config.Client,
-		url:                        config.URL,
-		start:                      config.Start,
-		boundary:                   config.Boundary,
-		headers:                    config.Headers,
-		delayBetweenBatch:          config.DelayBetweenBatch,
-		concurrent:                 config.Concurrent,
-		pointer:                    config.Pointer,
-		timeout:                    config.Timeout,
-		concurrentBatchWithContext: config.ConcurrentBatchWithContext,
-		ctx:                        ctx,
-		jsonPages:                  config.JsonPage,
-		visitor:                    config.visitor,
-	}
+	pag := config.build()
+	pag.concurrentBatchWithContext = config.ConcurrentBatchWithContext
+	pag.ctx = ctx
 
 	return pag.fillDefault(), nil
 }
 
+func (obj *PaginationAggregatorConfig) build() *PaginationAggregator {
+	return &PaginationAggregator{
+		client:             obj.Client,
+		template:           obj.template,
+		limit:              obj.Limit,
+		params:             obj.Params,
+		start:              obj.Start,
+		boundary:           obj.Boundary,
+		headers:            obj.Headers,
+		requestBuilder:     obj.RequestBuilder,
+		method:             strings.ToUpper(obj.Method),
+		body:               obj.body,
+		idempotencyKey:     obj.IdempotencyKey,
+		delayBetweenBatch:  obj.DelayBetweenBatch,
+		concurrent:         obj.Concurrent,
+		timeout:            obj.Timeout,
+		pointer:            obj.Pointer,
+		jsonPages:          obj.JsonPage,
+		headerPages:        obj.headerPage,
+		reevaluateBoundary: obj.ReevaluateBoundary,
+		boundaryChange:     obj.OnBoundaryChange,
+		terminators:        obj.terminators,
+		cursor:             obj.cursor,
+		transport:          obj.Transport,
+		jsonRPC:            obj.JsonRPC,
+		jsonRPCParams:      obj.jsonRPCParams,
+		session:            obj.Session,
+		tokenExpiry:        obj.TokenExpiry,
+		rangePages:         obj.Range,
+		timeWindow:         obj.TimeWindow,
+		keys:               obj.keys,
+		matrix:             obj.Matrix,
+		config:             obj,
+		itemsPath:          obj.itemsPath,
+		nested:             obj.Nested,
+		enrichment:         obj.Enrichment,
+		enrichmentURL:      obj.enrichmentURL,
+		decoder:            obj.decoder,
+		partitions:         obj.Partitions,
+		partitionProgress:  obj.OnPartitionProgress,
+		plugins:            obj.plugins,
+	}
+}
+
 func (obj *PaginationAggregatorConfig) tidyUpConfigurations() error {
 
-	if obj.Boundary == 0 {
-		obj.visitor = append(obj.visitor, newBoundaryAssertion())
+	obj.cursor = obj.Cursor
+	obj.keys = obj.KeyGenerator
+	obj.headerPage = obj.HeaderPage
+	obj.terminators = append([]Terminator{}, obj.Terminators...)
+	obj.itemsPath = obj.ItemsPath
+	obj.decoder = obj.Decoder
+
+	if err := obj.tidyUpRange(); err != nil {
+		return err
+	}
+
+	if err := validateTransport(obj.Transport, obj.Cursor); err != nil {
+		return err
+	}
+
+	if obj.Transport != nil {
+		obj.cursor = pageToken{}
+	}
+
+	if err := validateSession(obj.Session, obj.cursor); err != nil {
+		return err
+	}
+
+	if obj.Session != nil && obj.cursor == nil {
+		obj.cursor = sessionCursor{path: obj.Session.CursorPath}
+	}
+
+	if len(obj.Keys) > 0 && obj.keys == nil {
+		obj.keys = KeysOf(obj.Keys...)
+	}
+
+	// plugins of user run before built-in plugins (e.g. login step before boundary discovery)
+	obj.plugins = append([]Plugin{}, obj.Plugins...)
+
+	if obj.ExportJob != nil {
+
+		if err := obj.ExportJob.validate(); err != nil {
+			return err
+		}
+
+		obj.plugins = append(obj.plugins, obj.ExportJob)
+	}
+
+	if obj.Boundary == 0 && obj.cursor == nil && obj.TimeWindow == nil && obj.keys == nil && obj.JsonRPC == nil && obj.ExportJob == nil {
+		obj.plugins = append(obj.plugins, newBoundaryAssertion())
 	}
 
-	if obj.URL == "" {
+	if obj.ReevaluateBoundary && obj.JsonPage == nil && obj.headerPage == nil {
+		return errors.New("No Json Page Or Header Page Found To Reevaluate Boundary")
+	}
+
+	if obj.URL == "" && obj.RequestBuilder == nil && obj.Transport == nil && obj.ExportJob == nil {
 		return errors.New("No Http URL Found")
 	}
 
-	if obj.Client == nil {
+	if err := obj.tidyUpTemplate(); err != nil {
+		return err
+	}
+
+	if cursor, ok := obj.cursor.(validator); ok {
+		if err := cursor.validate(); err != nil {
+			return err
+		}
+	}
+
+	if cursor, ok := obj.cursor.(*XMLToken); ok {
+		obj.tidyUpXMLToken(cursor)
+	}
+
+	if obj.TimeWindow != nil {
+		if err := obj.TimeWindow.validate(); err != nil {
+			return err
+		}
+	}
+
+	if err := validatePartitions(obj.Partitions, obj.cursor); err != nil {
+		return err
+	}
+
+	if err := validateTokenExpiry(obj.TokenExpiry, obj.cursor, obj.itemsPath, obj.Partitions); err != nil {
+		return err
+	}
+
+	if err := validateMatrix(obj.Matrix); err != nil {
+		return err
+	}
+
+	if err := validateNested(obj.Nested, obj.itemsPath); err != nil {
+		return err
+	}
+
+	enrichmentURL, err := validateEnrichment(obj.Enrichment, obj.itemsPath)
+
+	if err != nil {
+		return err
+	}
+
+	obj.enrichmentURL = enrichmentURL
+
+	if obj.Client == nil && obj.Transport == nil {
 		return errors.New("No Http Client Found")
 	}
 
 	return nil
 }
+
+func (obj *PaginationAggregatorConfig) tidyUpTemplate() error {
+
+	obj.template = newURLTemplate(obj.URL)
+
+	known := map[string]bool{
+		PLACEHOLDER_PAGE:    true,
+		PLACEHOLDER_POINTER: true,
+		PLACEHOLDER_OFFSET:  true,
+		PLACEHOLDER_LIMIT:   true,
+		PLACEHOLDER_CURSOR:  true,
+	}
+
+	if obj.keys != nil {
+		known[PLACEHOLDER_KEY] = true
+	}
+
+	if err := validateParams(obj.Params); err != nil {
+		return err
+	}
+
+	for dimension := range obj.Matrix {
+
+		if reservedPlaceholders[dimension] {
+			return errors.New("Matrix Dimension " + dimension + " Overrides Reserved Placeholder")
+		}
+
+		known[dimension] = true
+	}
+
+	if obj.TimeWindow != nil {
+		known[PLACEHOLDER_START_TIME] = true
+		known[PLACEHOLDER_END_TIME] = true
+	}
+
+	for key := range obj.Params {
+		known[key] = true
+	}
+
+	for _, partition := range obj.Partitions {
+
+		if err := validateParams(partition.Params); err != nil {
+			return err
+		}
+
+		for key := range partition.Params {
+			known[key] = true
+		}
+	}
+
+	if err := obj.template.validate(known); err != nil {
+		return err
+	}
+
+	if obj.JsonRPC != nil {
+
+		jsonRPCParams, err := obj.JsonRPC.validate(known)
+
+		if err != nil {
+			return err
+		}
+
+		obj.jsonRPCParams = jsonRPCParams
+	}
+
+	if obj.Body != "" {
+
+		obj.body = newBodyTemplate(obj.Body)
+
+		if err := obj.body.validate(known); err != nil {
+			return err
+		}
+	}
+
+	if obj.TimeWindow != nil && (!obj.usePlaceholder(PLACEHOLDER_START_TIME) || !obj.usePlaceholder(PLACEHOLDER_END_TIME)) {
+		return errors.New("No {start_time} And {end_time} Placeholder Found For Time Window")
+	}
+
+	if obj.keys != nil && !obj.usePlaceholder(PLACEHOLDER_KEY) {
+		return errors.New("No {key} Placeholder Found For Keys")
+	}
+
+	if obj.Limit == 0 && obj.usePlaceholder(PLACEHOLDER_OFFSET, PLACEHOLDER_LIMIT) {
+		return errors.New("No Limit Found For {offset} Or {limit} Placeholder")
+	}
+
+	return nil
+}
+
+func (obj *PaginationAggregatorConfig) usePlaceholder(names ...string) bool {
+
+	for _, name := range names {
+
+		if obj.template.hasPlaceholder(name) {
+			return true
+		}
+
+		if obj.body != nil && obj.body.hasPlaceholder(name) {
+			return true
+		}
+
+		if obj.jsonRPCParams != nil && obj.jsonRPCParams.hasPlaceholder(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (obj *PaginationAggregatorConfig) tidyUpRange() error {
+
+	if obj.Range == nil {
+		return nil
+	}
+
+	if err := obj.Range.validate(); err != nil {
+		return err
+	}
+
+	if obj.headerPage == nil && obj.JsonPage == nil {
+		obj.headerPage = obj.Range
+	}
+
+	obj.terminators = append(obj.terminators, StatusCodes(http.StatusRequestedRangeNotSatisfiable))
+
+	return nil
+}
+
+func (obj *PaginationAggregatorConfig) tidyUpXMLToken(cursor *XMLToken) {
+
+	if obj.itemsPath == "" {
+		obj.itemsPath = cursor.ItemsPath
+	}
+
+	if obj.decoder == nil {
+		obj.decoder = XMLDecoder
+	}
+}
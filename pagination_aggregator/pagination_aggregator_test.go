@@ -6,8 +6,12 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -248,6 +252,2955 @@ func TestGetWithtTimeoutContext(t *testing.T) {
 
 }
 
+func TestReevaluateBoundary(t *testing.T) {
+
+	t.Run("boundary extended from json page", func(t *testing.T) {
+
+		var changes [][2]int
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+			page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+			totalPages := 2
+
+			if page >= 2 {
+				totalPages = 4
+			}
+
+			json.NewEncoder(w).Encode(jsonTestStructPagePerPage{Page: page, TotalPages: totalPages})
+		}))
+		defer server.Close()
+
+		pag, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+			Client:             &http.Client{},
+			JsonPage:           &jsonTestStructPagePerPage{},
+			URL:                server.URL + "/data?page=%d",
+			Concurrent:         2,
+			ReevaluateBoundary: true,
+			OnBoundaryChange: func(previous, current int) {
+				changes = append(changes, [2]int{previous, current})
+			},
+		})
+
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		res, err := pag.Get()
+
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		if len(res) != 4 {
+			t.Errorf("Response collected not match, expected %d actual %d", 4, len(res))
+		}
+
+		if len(changes) != 1 || changes[0] != [2]int{2, 4} {
+			t.Errorf("Boundary changes not match, expected %v actual %v", [][2]int{{2, 4}}, changes)
+		}
+	})
+
+	t.Run("boundary shrunk from header page", func(t *testing.T) {
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+			page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+
+			if page >= 2 {
+				w.Header().Set("X-Total-Pages", "2")
+			} else {
+				w.Header().Set("X-Total-Pages", "4")
+			}
+
+			fmt.Fprintf(w, "{}")
+		}))
+		defer server.Close()
+
+		pag, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+			Client:             &http.Client{},
+			HeaderPage:         headerTestTotalPages{},
+			URL:                server.URL + "/data?page=%d",
+			Concurrent:         2,
+			ReevaluateBoundary: true,
+		})
+
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		res, err := pag.Get()
+
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		if len(res) != 2 {
+			t.Errorf("Response collected not match, expected %d actual %d", 2, len(res))
+		}
+	})
+}
+
+func TestTerminators(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+
+		switch {
+		case page == 4:
+			w.WriteHeader(http.StatusNotFound)
+		case page >= 3:
+			json.NewEncoder(w).Encode(jsonTestStructPagePerPage{Page: 3, TotalPages: 10})
+		default:
+			json.NewEncoder(w).Encode(jsonTestStructPagePerPage{
+				Page:       page,
+				TotalPages: 10,
+				Animals:    []animal{{Id: page, Animal: "Anaconda"}},
+			})
+		}
+	}))
+	defer server.Close()
+
+	tables := []struct {
+		name       string
+		terminator Terminator
+		expected   int
+	}{
+		{name: "empty items", terminator: EmptyItems("data"), expected: 3},
+		{name: "status codes", terminator: StatusCodes(http.StatusNotFound), expected: 4},
+		{name: "max items", terminator: MaxItems(2, "data"), expected: 2},
+		{name: "max pages", terminator: MaxPages(1), expected: 1},
+		{name: "repeated page", terminator: RepeatedPage(), expected: 5},
+		{name: "stop when", terminator: StopWhen("data", func(item interface{}) bool {
+			return item.(map[string]interface{})["id"].(float64) == 1
+		}), expected: 1},
+	}
+
+	for _, table := range tables {
+		t.Run(table.name, func(t *testing.T) {
+
+			pag, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+				Client:      &http.Client{},
+				URL:         server.URL + "/data?page=%d",
+				Boundary:    10,
+				Concurrent:  10,
+				Terminators: []Terminator{table.terminator},
+			})
+
+			if err != nil {
+				t.Fatalf(err.Error())
+			}
+
+			// terminator state is not carried over to next run
+			for run := 0; run < 2; run++ {
+
+				res, err := pag.Get()
+
+				if err != nil {
+					t.Fatalf(err.Error())
+				}
+
+				if len(res) != table.expected {
+					t.Errorf("Response collected not match, expected %d actual %d", table.expected, len(res))
+				}
+			}
+		})
+	}
+
+	t.Run("Max items trimmed", func(t *testing.T) {
+
+		items := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{"data": [{"id": 1}, {"id": 2}, {"id": 3}]}`)
+		}))
+		defer items.Close()
+
+		pag, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+			Client:      &http.Client{},
+			URL:         items.URL + "/data?page=%d",
+			Boundary:    10,
+			Concurrent:  10,
+			Terminators: []Terminator{MaxItems(4, "data")},
+		})
+
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		res, err := pag.Get()
+
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		collected := 0
+
+		for _, val := range res {
+			if val.Response.Items == nil {
+				collected += 3
+			} else {
+				collected += len(val.Response.Items)
+			}
+		}
+
+		if len(res) != 2 || collected != 4 {
+			t.Errorf("Items collected not match, expected %d actual %d", 4, collected)
+		}
+	})
+}
+
+func TestNamedURLPlaceholders(t *testing.T) {
+
+	var requested []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.RawQuery)
+		fmt.Fprintf(w, "{}")
+	}))
+	defer server.Close()
+
+	pag, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+		Client:     &http.Client{},
+		URL:        server.URL + "/data?filter=name%3Dx&offset={offset}&limit={limit}&page={page}&q={query}",
+		Limit:      10,
+		Params:     map[string]string{"query": "a&b c"},
+		Boundary:   2,
+		Concurrent: 1,
+	})
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if _, err = pag.Get(); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	expected := map[string]bool{
+		"filter=name%3Dx&offset=0&limit=10&page=1&q=a%26b+c":  true,
+		"filter=name%3Dx&offset=10&limit=10&page=2&q=a%26b+c": true,
+	}
+
+	for _, query := range requested {
+		if !expected[query] {
+			t.Errorf("Requested query not match, actual %s", query)
+		}
+	}
+
+	t.Run("Config error unknown placeholder", func(t *testing.T) {
+		_, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+			Client: &http.Client{},
+			URL:    server.URL + "/data?page={page}&size={size}",
+		})
+		if err == nil {
+			t.Errorf("Error must not be null")
+		}
+	})
+
+	t.Run("Config error limit not set", func(t *testing.T) {
+		_, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+			Client: &http.Client{},
+			URL:    server.URL + "/data?offset={offset}",
+		})
+		if err == nil {
+			t.Errorf("Error must not be null")
+		}
+	})
+
+	t.Run("Legacy placeholder keep escaped characters", func(t *testing.T) {
+
+		pag, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+			Client:   &http.Client{},
+			URL:      server.URL + "/data?filter=name%3Dx&page=%d",
+			Boundary: 1,
+		})
+
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		res, err := pag.Get()
+
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		if query := res[0].Request.HttpRequest.URL.RawQuery; query != "filter=name%3Dx&page=1" {
+			t.Errorf("Requested query not match, actual %s", query)
+		}
+	})
+
+	t.Run("Config error param override reserved placeholder", func(t *testing.T) {
+		_, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+			Client:   &http.Client{},
+			URL:      server.URL + "/data?page={page}",
+			Params:   map[string]string{"page": "7"},
+			Boundary: 1,
+		})
+		if err == nil {
+			t.Errorf("Error must not be null")
+		}
+	})
+}
+
+func TestRequestBuilder(t *testing.T) {
+
+	builderErr := errors.New("Unable to sign request")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		if r.Header.Get("X-Signature") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		json.NewEncoder(w).Encode(jsonTestStructPagePerPage{TotalPages: 3})
+	}))
+	defer server.Close()
+
+	pag, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+		Client:     &http.Client{},
+		JsonPage:   &jsonTestStructPagePerPage{},
+		Concurrent: 3,
+		RequestBuilder: func(ctx context.Context, pointer int) (*http.Request, error) {
+
+			if pointer == 2 {
+				return nil, builderErr
+			}
+
+			req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/data?page=%d", server.URL, pointer), nil)
+
+			if err != nil {
+				return nil, err
+			}
+
+			req.Header.Set("X-Signature", strconv.Itoa(pointer))
+
+			return req, nil
+		},
+	})
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	res, err := pag.Get()
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if len(res) != 3 {
+		t.Fatalf("Response collected not match, expected %d actual %d", 3, len(res))
+	}
+
+	for _, val := range res {
+
+		if val.Request.Pointer == 2 && !errors.Is(val.Response.Error, builderErr) {
+			t.Errorf("Request builder error not surfaced on page %d", val.Request.Pointer)
+		}
+
+		if val.Request.Pointer != 2 && val.Response.Error != nil {
+			t.Errorf("Page %d must not error, actual %s", val.Request.Pointer, val.Response.Error.Error())
+		}
+	}
+}
+
+func TestPostWithBodyTemplate(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		var body struct {
+			From int `json:"from"`
+			Size int `json:"size"`
+		}
+
+		if r.Method != http.MethodPost || r.Header.Get(IDEMPOTENCY_KEY_HEADER) == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]int{"from": body.From, "size": body.Size})
+	}))
+	defer server.Close()
+
+	pag, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+		Client:   &http.Client{},
+		URL:      server.URL + "/search",
+		Method:   "post",
+		Body:     `{"from": {offset}, "size": {limit}}`,
+		Limit:    100,
+		Boundary: 3,
+		IdempotencyKey: func(pointer int) string {
+			return "search-" + strconv.Itoa(pointer)
+		},
+	})
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	res, err := pag.Get()
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if len(res) != 3 {
+		t.Errorf("Response collected not match, expected %d actual %d", 3, len(res))
+	}
+
+	for _, val := range res {
+
+		if val.Response.Error != nil {
+			t.Fatalf("Page %d must not error, actual %s", val.Request.Pointer, val.Response.Error.Error())
+		}
+
+		expected := fmt.Sprintf(`{"from":%d,"size":100}`, (val.Request.Pointer-1)*100)
+
+		if strings.TrimSpace(val.Response.Data) != expected {
+			t.Errorf("Request body not match, expected %s actual %s", expected, val.Response.Data)
+		}
+
+		if val.Request.HttpRequest.GetBody == nil {
+			t.Errorf("Request body of page %d is not replayable", val.Request.Pointer)
+		}
+	}
+
+	t.Run("Quoted param value is escaped", func(t *testing.T) {
+
+		echo := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+			var body map[string]string
+
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			fmt.Fprint(w, body["query"])
+		}))
+		defer echo.Close()
+
+		query := `name = "a\\b" <c>`
+
+		pag, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+			Client:   &http.Client{},
+			URL:      echo.URL + "/search",
+			Method:   "post",
+			Body:     `{"query": "{query}"}`,
+			Params:   map[string]string{"query": query},
+			Boundary: 1,
+		})
+
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		res, err := pag.Get()
+
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		if res[0].Response.Error != nil || res[0].Response.Data != query {
+			t.Errorf("Request body not match, expected %s actual %s", query, res[0].Response.Data)
+		}
+	})
+
+	t.Run("Config error no limit for body placeholder", func(t *testing.T) {
+		_, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+			Client:   &http.Client{},
+			URL:      server.URL + "/search",
+			Method:   "post",
+			Body:     `{"from": {offset}}`,
+			Boundary: 1,
+		})
+		if err == nil {
+			t.Errorf("Error must not be null")
+		}
+	})
+}
+
+func TestGraphQLPagination(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		var request graphQLRequest
+
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if request.Variables["owner"] == "unknown" {
+			fmt.Fprintf(w, `{"data": null, "errors": [{"message": "Could not resolve to a User"}]}`)
+			return
+		}
+
+		cursors := map[interface{}]string{
+			nil:  `{"hasNextPage": true, "endCursor": "c1"}`,
+			"c1": `{"hasNextPage": true, "endCursor": "c2"}`,
+			"c2": `{"hasNextPage": false, "endCursor": "c3"}`,
+		}
+
+		fmt.Fprintf(w, `{"data": {"issues": {"pageInfo": %s}, "rateLimit": {"remaining": 10}}}`, cursors[request.Variables["after"]])
+	}))
+	defer server.Close()
+
+	t.Run("follow page info", func(t *testing.T) {
+
+		paced := 0
+
+		pag, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+			Client:     &http.Client{},
+			URL:        server.URL + "/graphql",
+			Concurrent: 2,
+			Cursor: &GraphQL{
+				Query:         "query($owner: String, $after: String) { issues(owner: $owner, after: $after) { pageInfo { hasNextPage endCursor } } }",
+				Variables:     map[string]interface{}{"owner": "octocat"},
+				PageInfoPath:  "data.issues.pageInfo",
+				RateLimitPath: "data.rateLimit",
+				Pace: func(rateLimit interface{}) time.Duration {
+					paced++
+					return 0
+				},
+			},
+		})
+
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		res, err := pag.Get()
+
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		if len(res) != 3 || paced != 3 {
+			t.Fatalf("Response collected not match, expected %d actual %d paced %d", 3, len(res), paced)
+		}
+
+		for idx, cursor := range []string{"", "c1", "c2"} {
+			if res[idx].Request.Cursor != cursor {
+				t.Errorf("Requested cursor not match, expected %s actual %s", cursor, res[idx].Request.Cursor)
+			}
+		}
+	})
+
+	t.Run("errors on http 200", func(t *testing.T) {
+
+		pag, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+			Client: &http.Client{},
+			URL:    server.URL + "/graphql",
+			Cursor: &GraphQL{
+				Query:        "query($owner: String, $after: String) { issues(owner: $owner, after: $after) { pageInfo { hasNextPage endCursor } } }",
+				Variables:    map[string]interface{}{"owner": "unknown"},
+				PageInfoPath: "data.issues.pageInfo",
+			},
+		})
+
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		res, err := pag.Get()
+
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		if len(res) != 1 || res[0].Response.Error == nil {
+			t.Errorf("GraphQL errors must be recorded as page failure")
+		}
+	})
+
+	t.Run("Config error no page info path", func(t *testing.T) {
+		_, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+			Client: &http.Client{},
+			URL:    server.URL + "/graphql",
+			Cursor: &GraphQL{Query: "query { viewer { login } }"},
+		})
+		if err == nil {
+			t.Errorf("Error must not be null")
+		}
+	})
+}
+
+func TestHeaderCursorPagination(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		tokens := map[string]string{"": "t1", "t1": "t2"}
+
+		token := r.URL.Query().Get("page_token")
+
+		if r.URL.Path == "/header" {
+			token = r.Header.Get("X-Page-Token")
+		}
+
+		if next, ok := tokens[token]; ok {
+			w.Header().Set("X-Next-Page-Token", next)
+		}
+
+		fmt.Fprintf(w, `{"token": "%s"}`, token)
+	}))
+	defer server.Close()
+
+	tables := []struct {
+		name     string
+		url      string
+		cursor   *HeaderCursor
+		rawQuery string
+	}{
+		{name: "token as query param", url: server.URL + "/query", cursor: &HeaderCursor{ResponseHeader: "X-Next-Page-Token", QueryParam: "page_token"}},
+		{name: "token as request header", url: server.URL + "/header", cursor: &HeaderCursor{ResponseHeader: "X-Next-Page-Token", RequestHeader: "X-Page-Token"}},
+		{name: "other params kept as written", url: server.URL + "/query?z=1&filter=name%3Dx&page_token=", cursor: &HeaderCursor{ResponseHeader: "X-Next-Page-Token", QueryParam: "page_token"}, rawQuery: "z=1&filter=name%3Dx&page_token=t2"},
+	}
+
+	for _, table := range tables {
+		t.Run(table.name, func(t *testing.T) {
+
+			pag, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+				Client: &http.Client{},
+				URL:    table.url,
+				Cursor: table.cursor,
+			})
+
+			if err != nil {
+				t.Fatalf(err.Error())
+			}
+
+			res, err := pag.Get()
+
+			if err != nil {
+				t.Fatalf(err.Error())
+			}
+
+			if len(res) != 3 {
+				t.Fatalf("Response collected not match, expected %d actual %d", 3, len(res))
+			}
+
+			if res[2].Response.Data != `{"token": "t2"}` {
+				t.Errorf("Last page token not match, actual %s", res[2].Response.Data)
+			}
+
+			if table.rawQuery != "" && res[2].Request.HttpRequest.URL.RawQuery != table.rawQuery {
+				t.Errorf("Query not match, expected %s actual %s", table.rawQuery, res[2].Request.HttpRequest.URL.RawQuery)
+			}
+		})
+	}
+
+	t.Run("Config error token never sent", func(t *testing.T) {
+
+		_, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+			Client: &http.Client{},
+			URL:    server.URL + "/query",
+			Cursor: &HeaderCursor{ResponseHeader: "X-Next-Page-Token"},
+		})
+
+		if err == nil {
+			t.Errorf("Error must not be null")
+		}
+	})
+}
+
+func TestRangePagination(t *testing.T) {
+
+	total := 250
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		var first, last int
+
+		if _, err := fmt.Sscanf(r.Header.Get("Range"), "items=%d-%d", &first, &last); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		items := total
+
+		if r.URL.Path == "/empty" {
+			items = 0
+		}
+
+		if first >= items {
+			w.Header().Set("Content-Range", fmt.Sprintf("items */%d", items))
+			w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+			return
+		}
+
+		if last >= items {
+			last = items - 1
+		}
+
+		size := strconv.Itoa(items)
+
+		if r.URL.Path == "/unknown" {
+			size = "*"
+		}
+
+		w.Header().Set("Content-Range", fmt.Sprintf("items %d-%d/%s", first, last, size))
+		w.WriteHeader(http.StatusPartialContent)
+		fmt.Fprintf(w, "[]")
+	}))
+	defer server.Close()
+
+	tables := []struct {
+		name     string
+		url      string
+		expected int
+	}{
+		{name: "total from content range", url: server.URL + "/known", expected: 3},
+		{name: "unknown total stop on 416", url: server.URL + "/unknown", expected: 4},
+		{name: "empty collection", url: server.URL + "/empty", expected: 0},
+	}
+
+	for _, table := range tables {
+		t.Run(table.name, func(t *testing.T) {
+
+			pag, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+				Client: &http.Client{},
+				URL:    table.url,
+				Range:  &RangePagination{Size: 100},
+			})
+
+			if err != nil {
+				t.Fatalf(err.Error())
+			}
+
+			res, err := pag.Get()
+
+			if err != nil {
+				t.Fatalf(err.Error())
+			}
+
+			if len(res) != table.expected {
+				t.Fatalf("Response collected not match, expected %d actual %d", table.expected, len(res))
+			}
+
+			for _, val := range res {
+				if val.Request.Pointer <= 3 && val.Response.Status != http.StatusPartialContent {
+					t.Errorf("Page %d status not match, expected %d actual %d", val.Request.Pointer, http.StatusPartialContent, val.Response.Status)
+				}
+			}
+		})
+	}
+}
+
+func TestTimeWindowPagination(t *testing.T) {
+
+	from := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	resultCap := 3
+
+	events := []time.Time{
+		from.Add(10 * time.Minute),
+		from.Add(60 * time.Minute),
+		from.Add(70 * time.Minute),
+		from.Add(80 * time.Minute),
+		from.Add(100 * time.Minute),
+		from.Add(110 * time.Minute),
+		from.Add(190 * time.Minute),
+		from.Add(200 * time.Minute),
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		var found []string
+
+		start, _ := time.Parse(time.RFC3339, r.URL.Query().Get("start_time"))
+		end, _ := time.Parse(time.RFC3339, r.URL.Query().Get("end_time"))
+
+		for _, event := range events {
+			if !event.Before(start) && event.Before(end) && len(found) < resultCap {
+				found = append(found, event.Format(time.RFC3339))
+			}
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{"events": found})
+	}))
+	defer server.Close()
+
+	pag, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+		Client: &http.Client{},
+		URL:    server.URL + "/audit?start_time={start_time}&end_time={end_time}",
+		TimeWindow: &TimeWindow{
+			From:      from,
+			To:        from.Add(4 * time.Hour),
+			Window:    time.Hour,
+			Cap:       resultCap,
+			ItemsPath: "events",
+		},
+	})
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	res, err := pag.Get()
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	collected := map[string]bool{}
+
+	for _, val := range res {
+
+		tree, err := val.Response.Decode()
+
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		items, _ := lookupItems(tree, "events")
+
+		if len(items) >= resultCap {
+			t.Errorf("Window %s - %s hit result cap", val.Request.Window.Start, val.Request.Window.End)
+		}
+
+		for _, item := range items {
+			collected[item.(string)] = true
+		}
+	}
+
+	if len(collected) != len(events) {
+		t.Errorf("Events collected not match, expected %d actual %d", len(events), len(collected))
+	}
+
+	t.Run("Window truncated at min window", func(t *testing.T) {
+
+		pag, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+			Client: &http.Client{},
+			URL:    server.URL + "/audit?start_time={start_time}&end_time={end_time}",
+			TimeWindow: &TimeWindow{
+				From:      from,
+				To:        from.Add(4 * time.Hour),
+				Window:    time.Hour,
+				Cap:       resultCap,
+				ItemsPath: "events",
+				MinWindow: time.Hour,
+			},
+		})
+
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		res, err := pag.Get()
+
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		for _, val := range res {
+
+			truncated := val.Request.Window.Start.Equal(from.Add(time.Hour))
+
+			if val.Request.Window.Truncated != truncated {
+				t.Errorf("Window %s - %s truncated not match, expected %t", val.Request.Window.Start, val.Request.Window.End, truncated)
+			}
+		}
+	})
+
+	t.Run("Config error no time placeholder", func(t *testing.T) {
+		_, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+			Client: &http.Client{},
+			URL:    server.URL + "/audit?start_time={start_time}",
+			TimeWindow: &TimeWindow{
+				From:   from,
+				To:     from.Add(4 * time.Hour),
+				Window: time.Hour,
+			},
+		})
+		if err == nil {
+			t.Errorf("Error must not be null")
+		}
+	})
+}
+
+func TestPartitionedCursorPagination(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		tokens := map[string]string{"": "t1", "t1": "t2"}
+
+		if next, ok := tokens[r.URL.Query().Get("page_token")]; ok {
+			w.Header().Set("X-Next-Page-Token", next)
+		}
+
+		fmt.Fprintf(w, `{"tenant": "%s"}`, r.URL.Query().Get("tenant"))
+	}))
+	defer server.Close()
+
+	checkpoints := map[string]PartitionProgress{}
+
+	pag, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+		Client:     &http.Client{},
+		URL:        server.URL + "/items?tenant={tenant}",
+		Concurrent: 2,
+		Cursor:     &HeaderCursor{ResponseHeader: "X-Next-Page-Token", QueryParam: "page_token"},
+		Partitions: []Partition{
+			{Name: "a", Params: map[string]string{"tenant": "a"}},
+			{Name: "b", Params: map[string]string{"tenant": "b"}},
+			{Name: "c", Params: map[string]string{"tenant": "c"}, Cursor: "t1"},
+		},
+		OnPartitionProgress: func(progress PartitionProgress) {
+			checkpoints[progress.Partition] = progress
+		},
+	})
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	res, err := pag.Get()
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	collected := map[string]int{}
+
+	for _, val := range res {
+
+		if val.Response.Data != fmt.Sprintf(`{"tenant": "%s"}`, val.Request.Partition) {
+			t.Errorf("Partition params not match, partition %s actual %s", val.Request.Partition, val.Response.Data)
+		}
+
+		collected[val.Request.Partition]++
+	}
+
+	expected := map[string]int{"a": 3, "b": 3, "c": 2}
+
+	for name, pages := range expected {
+
+		if collected[name] != pages || checkpoints[name].Pages != pages || !checkpoints[name].Done {
+			t.Errorf("Partition %s pages not match, expected %d actual %d", name, pages, collected[name])
+		}
+	}
+
+	t.Run("Checkpoint reported after page is delivered", func(t *testing.T) {
+
+		delivered := map[string]int{}
+
+		pag, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+			Client:     &http.Client{},
+			URL:        server.URL + "/items?tenant={tenant}",
+			Concurrent: 2,
+			Cursor:     &HeaderCursor{ResponseHeader: "X-Next-Page-Token", QueryParam: "page_token"},
+			Partitions: []Partition{
+				{Name: "a", Params: map[string]string{"tenant": "a"}},
+				{Name: "b", Params: map[string]string{"tenant": "b"}},
+			},
+			ConcurrentBatch: func(batch []HttpInteraction) error {
+				for _, val := range batch {
+					delivered[val.Request.Partition]++
+				}
+				return nil
+			},
+			OnPartitionProgress: func(progress PartitionProgress) {
+				if progress.Pages > delivered[progress.Partition] {
+					t.Errorf("Partition %s checkpoint ahead of delivered pages, expected %d actual %d", progress.Partition, delivered[progress.Partition], progress.Pages)
+				}
+			},
+		})
+
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		if _, err := pag.Get(); err != nil {
+			t.Fatalf(err.Error())
+		}
+	})
+
+	t.Run("Config error no cursor strategy", func(t *testing.T) {
+		_, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+			Client:     &http.Client{},
+			URL:        server.URL + "/items?page=%d",
+			Boundary:   1,
+			Partitions: []Partition{{Name: "a"}},
+		})
+		if err == nil {
+			t.Errorf("Error must not be null")
+		}
+	})
+}
+
+func TestIterateKeys(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"key": "%s"}`, r.URL.Query().Get("key"))
+	}))
+	defer server.Close()
+
+	from := time.Date(2023, 1, 30, 0, 0, 0, 0, time.UTC)
+
+	tables := []struct {
+		name      string
+		keys      []string
+		generator KeyGenerator
+		expected  []string
+	}{
+		{name: "list of keys", keys: []string{"eu west", "us", "ap"}, expected: []string{"eu west", "us", "ap"}},
+		{name: "date keys", generator: DateKeys(from, from.AddDate(0, 0, 3), 24*time.Hour, "2006-01-02"), expected: []string{"2023-01-30", "2023-01-31", "2023-02-01"}},
+	}
+
+	for _, table := range tables {
+		t.Run(table.name, func(t *testing.T) {
+
+			pag, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+				Client:       &http.Client{},
+				URL:          server.URL + "/items?key={key}",
+				Keys:         table.keys,
+				KeyGenerator: table.generator,
+			})
+
+			if err != nil {
+				t.Fatalf(err.Error())
+			}
+
+			res, err := pag.Get()
+
+			if err != nil {
+				t.Fatalf(err.Error())
+			}
+
+			if len(res) != len(table.expected) {
+				t.Fatalf("Response collected not match, expected %d actual %d", len(table.expected), len(res))
+			}
+
+			for idx, val := range res {
+
+				if val.Request.Key != table.expected[idx] || val.Response.Data != fmt.Sprintf(`{"key": "%s"}`, table.expected[idx]) {
+					t.Errorf("Requested key not match, expected %s actual %s", table.expected[idx], val.Request.Key)
+				}
+			}
+		})
+	}
+
+	t.Run("Config error no key placeholder", func(t *testing.T) {
+
+		_, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+			Client: &http.Client{},
+			URL:    server.URL + "/items",
+			Keys:   []string{"eu", "us"},
+		})
+
+		if err == nil {
+			t.Errorf("Error must not be null")
+		}
+	})
+}
+
+func TestParameterMatrix(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		totalPages := map[string]int{"a": 2, "b": 1}[r.URL.Query().Get("project")]
+
+		json.NewEncoder(w).Encode(jsonTestStructPagePerPage{Page: page, TotalPages: totalPages})
+	}))
+	defer server.Close()
+
+	batches := 0
+
+	pag, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+		Client:   &http.Client{},
+		URL:      server.URL + "/issues?project={project}&status={status}&page={page}",
+		JsonPage: &jsonTestStructPagePerPage{},
+		Matrix: map[string][]string{
+			"project": {"a", "b"},
+			"status":  {"open", "closed"},
+		},
+		ConcurrentBatch: func(batchResult []HttpInteraction) error {
+
+			for _, val := range batchResult {
+				if val.Request.Params["project"] == "" || val.Request.Params["status"] == "" {
+					t.Errorf("Batch result not tagged with matrix combination")
+				}
+			}
+
+			batches++
+
+			return nil
+		},
+	})
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	res, err := pag.Get()
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if len(res) != 6 || batches != 4 {
+		t.Fatalf("Response collected not match, expected %d actual %d with %d batches", 6, len(res), batches)
+	}
+
+	for _, val := range res {
+
+		query := val.Request.HttpRequest.URL.Query()
+
+		if query.Get("project") != val.Request.Params["project"] || query.Get("status") != val.Request.Params["status"] {
+			t.Errorf("Matrix combination not match with request %s", val.Request.HttpRequest.URL.RawQuery)
+		}
+	}
+
+	t.Run("Max pages of every combination", func(t *testing.T) {
+
+		pag, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+			Client:      &http.Client{},
+			URL:         server.URL + "/issues?project={project}&page={page}",
+			Boundary:    5,
+			Concurrent:  5,
+			Terminators: []Terminator{MaxPages(2)},
+			Matrix: map[string][]string{
+				"project": {"a", "b"},
+			},
+		})
+
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		for run := 0; run < 2; run++ {
+
+			res, err := pag.Get()
+
+			if err != nil {
+				t.Fatalf(err.Error())
+			}
+
+			if len(res) != 4 {
+				t.Errorf("Response collected not match, expected %d actual %d", 4, len(res))
+			}
+		}
+	})
+}
+
+func TestNestedPagination(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+
+		if r.URL.Path == "/repos" {
+			fmt.Fprintf(w, `{"total_pages": 2, "data": [{"id": %d, "owner": {"login": "octocat"}}, {"id": %d, "owner": {"login": "octocat"}}]}`, page*10+1, page*10+2)
+			return
+		}
+
+		json.NewEncoder(w).Encode(jsonTestStructPagePerPage{
+			Page:       page,
+			TotalPages: 2,
+			Animals:    []animal{{Id: page, Animal: r.URL.Path}},
+		})
+	}))
+	defer server.Close()
+
+	pag, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+		Client:     &http.Client{},
+		URL:        server.URL + "/repos?page={page}",
+		Boundary:   2,
+		Concurrent: 2,
+		ItemsPath:  "data",
+		Nested: []Nested{
+			{
+				Name: "issues",
+				Config: &PaginationAggregatorConfig{
+					URL:      server.URL + "/repos/{owner.login}/{id}/issues?page={page}",
+					JsonPage: &jsonTestStructPagePerPage{},
+				},
+			},
+		},
+	})
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	res, err := pag.Get()
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	children := 0
+
+	for _, val := range res {
+
+		if val.Parent == nil {
+			continue
+		}
+
+		children++
+
+		item := val.Parent.Item.(map[string]interface{})
+		expected := fmt.Sprintf("/repos/octocat/%v/issues", item["id"])
+
+		if val.Request.HttpRequest.URL.Path != expected || val.Parent.Name != "issues" || val.Parent.Interaction.Request.Pointer == 0 {
+			t.Errorf("Child interaction not linked to parent item, expected %s actual %s", expected, val.Request.HttpRequest.URL.Path)
+		}
+	}
+
+	// 2 parent pages with 2 items, each item has 2 child pages
+	if children != 8 || len(res) != 10 {
+		t.Errorf("Response collected not match, expected %d children actual %d", 8, children)
+	}
+
+	t.Run("Big item id and child failures", func(t *testing.T) {
+
+		big := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+			if r.URL.Path == "/repos" {
+				fmt.Fprint(w, `{"data": [{"id": 9007199254740993}]}`)
+				return
+			}
+
+			fmt.Fprintf(w, `{"path": "%s"}`, r.URL.Path)
+		}))
+		defer big.Close()
+
+		callbackErr := errors.New("Callback Failed")
+
+		pag, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+			Client:     &http.Client{},
+			URL:        big.URL + "/repos?page={page}",
+			Boundary:   1,
+			Concurrent: 2,
+			ItemsPath:  "data",
+			Nested: []Nested{
+				{
+					Name: "issues",
+					Config: &PaginationAggregatorConfig{
+						URL:        big.URL + "/repos/{id}/issues?page={page}",
+						Boundary:   2,
+						Concurrent: 1,
+						ConcurrentBatch: func(batchResult []HttpInteraction) error {
+							if batchResult[0].Request.Pointer == 2 {
+								return callbackErr
+							}
+							return nil
+						},
+					},
+				},
+				{
+					Name:   "unknown",
+					Config: &PaginationAggregatorConfig{URL: big.URL + "/repos/{id}/{unknown}?page={page}", Boundary: 1},
+				},
+			},
+		})
+
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		res, err := pag.Get()
+
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		failures := map[string]error{}
+
+		for _, val := range res {
+
+			if val.Parent == nil {
+				continue
+			}
+
+			if val.Request.HttpRequest == nil || val.Request.Pointer == 0 {
+				t.Fatalf("Child interaction of %s has no request", val.Parent.Name)
+			}
+
+			if !strings.HasPrefix(val.Request.HttpRequest.URL.Path, "/repos/9007199254740993/") {
+				t.Errorf("Child request not match, actual %s", val.Request.HttpRequest.URL.Path)
+			}
+
+			if val.Response.Error != nil {
+				failures[val.Parent.Name] = val.Response.Error
+			}
+		}
+
+		if !errors.Is(failures["issues"], callbackErr) || failures["unknown"] == nil {
+			t.Errorf("Child failures not match, actual %v", failures)
+		}
+	})
+
+	t.Run("Boundary reevaluated on parent pages only", func(t *testing.T) {
+
+		reevaluated := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+			if r.URL.Path == "/repos" {
+				fmt.Fprintf(w, `{"total_pages": 2, "data": [{"id": %s}]}`, r.URL.Query().Get("page"))
+				return
+			}
+
+			fmt.Fprint(w, `{"total_pages": 50}`)
+		}))
+		defer reevaluated.Close()
+
+		var changes [][2]int
+
+		pag, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+			Client:             &http.Client{},
+			URL:                reevaluated.URL + "/repos?page={page}",
+			JsonPage:           &jsonTestStructPagePerPage{},
+			Concurrent:         2,
+			ItemsPath:          "data",
+			ReevaluateBoundary: true,
+			OnBoundaryChange: func(previous, current int) {
+				changes = append(changes, [2]int{previous, current})
+			},
+			Nested: []Nested{
+				{
+					Name:   "issues",
+					Config: &PaginationAggregatorConfig{URL: reevaluated.URL + "/repos/{id}/issues?page={page}", Boundary: 3},
+				},
+			},
+		})
+
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		res, err := pag.Get()
+
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		parents := 0
+
+		for _, val := range res {
+			if val.Parent == nil {
+				parents++
+			}
+		}
+
+		if parents != 2 || len(changes) != 0 {
+			t.Errorf("Parent pages not match, expected %d actual %d boundary changes %v", 2, parents, changes)
+		}
+	})
+
+	t.Run("Enriched field on nested placeholder", func(t *testing.T) {
+
+		var enriched *httptest.Server
+
+		enriched = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+			switch r.URL.Path {
+			case "/repos":
+				fmt.Fprintf(w, `{"data": [{"id": 9007199254740993, "url": "%s/detail"}]}`, enriched.URL)
+			case "/detail":
+				fmt.Fprint(w, `{"login": "octocat"}`)
+			default:
+				fmt.Fprintf(w, `{"path": "%s"}`, r.URL.Path)
+			}
+		}))
+		defer enriched.Close()
+
+		pag, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+			Client:     &http.Client{},
+			URL:        enriched.URL + "/repos?page={page}",
+			Boundary:   1,
+			ItemsPath:  "data",
+			Enrichment: &Enrichment{URLField: "url"},
+			Nested: []Nested{
+				{Name: "repos", Config: &PaginationAggregatorConfig{URL: enriched.URL + "/users/{login}/{id}?page={page}", Boundary: 1}},
+			},
+		})
+
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		res, err := pag.Get()
+
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		if len(res) != 2 || res[1].Request.HttpRequest.URL.Path != "/users/octocat/9007199254740993" {
+			t.Errorf("Child request not match, actual %d interactions", len(res))
+		}
+	})
+}
+
+func TestItemEnrichment(t *testing.T) {
+
+	var server *httptest.Server
+
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		switch r.URL.Path {
+		case "/pokemon":
+			fmt.Fprintf(w, `{"results": [{"name": "bulbasaur", "url": "%[1]s/pokemon/1"}, {"name": "missingno", "url": "%[1]s/pokemon/0"}]}`, server.URL)
+		case "/pokemon/1", "/pokemon/bulbasaur":
+			fmt.Fprintf(w, `{"id": 1, "height": 7}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tables := []struct {
+		name       string
+		enrichment *Enrichment
+		height     func(item map[string]interface{}) interface{}
+	}{
+		{
+			name:       "merge detail from url field",
+			enrichment: &Enrichment{URLField: "url"},
+			height: func(item map[string]interface{}) interface{} {
+				return item["height"]
+			},
+		},
+		{
+			name:       "store detail from url template",
+			enrichment: &Enrichment{URL: server.URL + "/pokemon/{name}", Field: "detail"},
+			height: func(item map[string]interface{}) interface{} {
+				detail, _ := item["detail"].(map[string]interface{})
+				return detail["height"]
+			},
+		},
+	}
+
+	for _, table := range tables {
+		t.Run(table.name, func(t *testing.T) {
+
+			pointers := &testPointerPlugin{}
+
+			pag, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+				Client:     &http.Client{},
+				URL:        server.URL + "/pokemon?page={page}",
+				Start:      3,
+				Boundary:   3,
+				ItemsPath:  "results",
+				Enrichment: table.enrichment,
+				Plugins:    []Plugin{pointers},
+			})
+
+			if err != nil {
+				t.Fatalf(err.Error())
+			}
+
+			res, err := pag.Get()
+
+			if err != nil {
+				t.Fatalf(err.Error())
+			}
+
+			items := res[0].Response.Items
+
+			if len(items) != 2 {
+				t.Fatalf("Items extracted not match, expected %d actual %d", 2, len(items))
+			}
+
+			if table.height(items[0].(map[string]interface{})) != float64(7) {
+				t.Errorf("Detail not merged into item, actual %v", items[0])
+			}
+
+			if len(res[0].Response.ItemErrors) != 1 || res[0].Response.ItemErrors[1] == nil {
+				t.Errorf("Failed detail request must be recorded on item errors")
+			}
+
+			// page and both detail requests are recorded with pointer of the page
+			if len(pointers.pointers) != 3 || pointers.pointers[0] != 3 || pointers.pointers[1] != 3 || pointers.pointers[2] != 3 {
+				t.Errorf("Detail request pointers not match, expected %d actual %v", 3, pointers.pointers)
+			}
+		})
+	}
+
+	t.Run("Missing item field on detail url", func(t *testing.T) {
+
+		pag, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+			Client:     &http.Client{},
+			URL:        server.URL + "/pokemon",
+			Boundary:   1,
+			ItemsPath:  "results",
+			Enrichment: &Enrichment{URL: server.URL + "/pokemon/{nickname}"},
+		})
+
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		res, err := pag.Get()
+
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		if len(res[0].Response.ItemErrors) != 2 {
+			t.Errorf("Item errors not match, expected %d actual %d", 2, len(res[0].Response.ItemErrors))
+		}
+	})
+
+	t.Run("Config error no placeholder on detail url", func(t *testing.T) {
+		_, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+			Client:     &http.Client{},
+			URL:        server.URL + "/pokemon",
+			Boundary:   1,
+			ItemsPath:  "results",
+			Enrichment: &Enrichment{URL: server.URL + "/pokemon/1"},
+		})
+		if err == nil {
+			t.Errorf("Error must not be null")
+		}
+	})
+}
+
+// record pointer of every page and detail request
+type testPointerPlugin struct {
+	BasePlugin
+	mutex    sync.Mutex
+	pointers []int
+}
+
+func (obj *testPointerPlugin) AfterResponse(state *State, interaction *HttpInteraction) error {
+
+	obj.mutex.Lock()
+	defer obj.mutex.Unlock()
+
+	obj.pointers = append(obj.pointers, interaction.Request.Pointer)
+
+	return nil
+}
+
+func TestJoinAggregations(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		page := r.URL.Query().Get("page")
+
+		switch r.URL.Path + "?" + page {
+		case "/users?1":
+			fmt.Fprintf(w, `{"data": [{"id": 1}, {"id": 2}]}`)
+		case "/users?2":
+			fmt.Fprintf(w, `{"data": [{"id": 3}, {"id": 4}]}`)
+		case "/accounts?1":
+			fmt.Fprintf(w, `{"data": [{"user_id": 2}, {"user_id": 3}]}`)
+		default:
+			fmt.Fprintf(w, `{"data": [{"user_id": 5}]}`)
+		}
+	}))
+	defer server.Close()
+
+	sides := func(matrix map[string][]string) []JoinSide {
+
+		users, _ := NewPaginationAggregator(&PaginationAggregatorConfig{
+			Client:    &http.Client{},
+			URL:       server.URL + "/users?page={page}",
+			Boundary:  2,
+			ItemsPath: "data",
+			Matrix:    matrix,
+		})
+
+		accounts, _ := NewPaginationAggregator(&PaginationAggregatorConfig{
+			Client:    &http.Client{},
+			URL:       server.URL + "/accounts?page={page}",
+			Boundary:  2,
+			ItemsPath: "data",
+			Matrix:    matrix,
+		})
+
+		return []JoinSide{
+			{Name: "users", Aggregator: users, Key: func(item interface{}) string {
+				return fmt.Sprint(item.(map[string]interface{})["id"])
+			}},
+			{Name: "accounts", Aggregator: accounts, Key: func(item interface{}) string {
+				return fmt.Sprint(item.(map[string]interface{})["user_id"])
+			}},
+		}
+	}
+
+	tables := []struct {
+		name     string
+		join     JoinType
+		spill    bool
+		matrix   map[string][]string
+		expected []string
+	}{
+		{name: "inner join", join: INNER_JOIN, expected: []string{"2", "3"}},
+		{name: "left join", join: LEFT_JOIN, expected: []string{"1", "2", "3", "4"}},
+		{name: "full join", join: FULL_JOIN, expected: []string{"1", "2", "3", "4", "5"}},
+		{name: "inner join spilled to disk", join: INNER_JOIN, spill: true, expected: []string{"2", "3"}},
+		{name: "inner join of matrix sides", join: INNER_JOIN, matrix: map[string][]string{"region": {"eu"}}, expected: []string{"2", "3"}},
+	}
+
+	for _, table := range tables {
+		t.Run(table.name, func(t *testing.T) {
+
+			var keys []string
+
+			join := NewJoin(table.join, sides(table.matrix)...)
+
+			if table.spill {
+				join.MaxItemsInMemory = 1
+				join.SpillDir = t.TempDir()
+				join.Buckets = 2
+			}
+
+			err := join.Run(func(record JoinedRecord) error {
+
+				if table.join == INNER_JOIN && (record.Items["users"] == nil || record.Items["accounts"] == nil) {
+					t.Errorf("Inner join record must have every side, actual %v", record.Items)
+				}
+
+				keys = append(keys, record.Key)
+
+				return nil
+			})
+
+			if err != nil {
+				t.Fatalf(err.Error())
+			}
+
+			sort.Strings(keys)
+
+			if strings.Join(keys, ",") != strings.Join(table.expected, ",") {
+				t.Errorf("Joined keys not match, expected %v actual %v", table.expected, keys)
+			}
+
+			// side aggregator collect its own result again after join
+			for _, side := range join.Sides {
+
+				res, err := side.Aggregator.Get()
+
+				if err != nil {
+					t.Fatalf(err.Error())
+				}
+
+				if len(res) != 2 {
+					t.Errorf("Response collected not match, expected %d actual %d", 2, len(res))
+				}
+			}
+		})
+	}
+}
+
+func TestMultiSourceAggregation(t *testing.T) {
+
+	stopErr := errors.New("Stop processing ap region")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		if r.URL.Path == "/us" && r.URL.Query().Get("page") == "2" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		fmt.Fprintf(w, `{"region": "%s"}`, r.URL.Path)
+	}))
+	defer server.Close()
+
+	streamed := 0
+
+	multi, err := NewMultiSourceAggregator(&MultiSourceConfig{
+		Concurrent: 2,
+		Sources: []Source{
+			{Name: "eu", Config: &PaginationAggregatorConfig{Client: &http.Client{}, URL: server.URL + "/eu?page=%d", Boundary: 3}},
+			{Name: "us", Config: &PaginationAggregatorConfig{Client: &http.Client{}, URL: server.URL + "/us?page=%d", Boundary: 2}},
+			{Name: "ap", Config: &PaginationAggregatorConfig{
+				Client:   &http.Client{},
+				URL:      server.URL + "/ap?page=%d",
+				Boundary: 2,
+				ConcurrentBatch: func(batchResult []HttpInteraction) error {
+					return stopErr
+				},
+			}},
+			{Name: "grid", Config: &PaginationAggregatorConfig{
+				Client:   &http.Client{},
+				URL:      server.URL + "/grid?project={project}&status={status}&page={page}",
+				Boundary: 1,
+				Matrix: map[string][]string{
+					"project": {"a", "b"},
+					"status":  {"open", "closed"},
+				},
+			}},
+		},
+		ConcurrentBatch: func(batchResult []HttpInteraction) error {
+			streamed += len(batchResult)
+			return nil
+		},
+	})
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// second run must not keep pages and errors of first run
+	for run := 0; run < 2; run++ {
+
+		streamed = 0
+
+		res, err := multi.Get()
+
+		assertMultiSourceRun(t, res, err, stopErr, streamed)
+	}
+}
+
+func assertMultiSourceRun(t *testing.T, res []HttpInteraction, err error, stopErr error, streamed int) {
+
+	var report *ErrorReport
+
+	if !errors.As(err, &report) {
+		t.Fatalf("Error must be error report, actual %v", err)
+	}
+
+	if len(report.Sources) != 1 || report.Sources[0].Source != "ap" || !errors.Is(report.Sources[0].Err, stopErr) {
+		t.Errorf("Failed sources not match, actual %v", report.Sources)
+	}
+
+	if len(report.Pages) != 1 || report.Pages[0].Request.Source != "us" {
+		t.Errorf("Failed pages not match, expected %d actual %d", 1, len(report.Pages))
+	}
+
+	if len(res) != 11 || streamed != 11 {
+		t.Errorf("Response collected not match, expected %d actual %d streamed %d", 11, len(res), streamed)
+	}
+
+	for _, val := range res {
+
+		if val.Response.Error == nil && val.Response.Data != fmt.Sprintf(`{"region": "/%s"}`, val.Request.Source) {
+			t.Errorf("Source tag not match, source %s actual %s", val.Request.Source, val.Response.Data)
+		}
+
+		if val.Request.Source == "grid" && len(val.Request.Params) != 2 {
+			t.Errorf("Matrix tag not match, expected %d actual %d", 2, len(val.Request.Params))
+		}
+	}
+}
+
+func TestResponseDecoders(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		switch r.URL.Path {
+		case "/xml":
+			w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+			fmt.Fprintf(w, `<page><total_pages>2</total_pages><data><animal><id>1</id></animal><animal><id>2</id></animal></data></page>`)
+		case "/xml-single":
+			w.Header().Set("Content-Type", "application/xml")
+			fmt.Fprintf(w, `<page><data><animal><id>1</id></animal></data></page>`)
+		case "/json-object":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"data": {"animal": {"id": 1}}}`)
+		case "/csv":
+			w.Header().Set("Content-Type", "text/csv")
+			fmt.Fprintf(w, "id,animal\n1,Anaconda\n2,Bird\n")
+		case "/ndjson":
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			fmt.Fprintf(w, "{\"id\": 1}\n{\"id\": 2}\n")
+		case "/msgpack":
+			w.Header().Set("Content-Type", "application/msgpack")
+			// {"total_pages": 2, "data": [{"id": 1}, {"id": -2}]}
+			w.Write(append(append([]byte{0x82, 0xab}, "total_pages"...), append(append([]byte{0x02, 0xa4}, "data"...),
+				0x92, 0x81, 0xa2, 'i', 'd', 0x01, 0x81, 0xa2, 'i', 'd', 0xfe)...))
+		default:
+			w.Header().Set("Content-Type", "text/plain")
+			fmt.Fprintf(w, "id\n1\n2\n")
+		}
+	}))
+	defer server.Close()
+
+	tables := []struct {
+		name      string
+		path      string
+		itemsPath string
+		jsonPage  JsonMetaPages
+		decoder   Decoder
+		pages     int
+	}{
+		{name: "xml", path: "/xml", itemsPath: "data.animal", jsonPage: &jsonTestStructPagePerPage{}, pages: 2},
+		{name: "csv", path: "/csv", itemsPath: ".", pages: 1},
+		{name: "ndjson", path: "/ndjson", itemsPath: ".", pages: 1},
+		{name: "msgpack", path: "/msgpack", itemsPath: "data", jsonPage: &jsonTestStructPagePerPage{}, pages: 2},
+		{name: "decoder override", path: "/plain", itemsPath: ".", decoder: CSVDecoder, pages: 1},
+	}
+
+	for _, table := range tables {
+		t.Run(table.name, func(t *testing.T) {
+
+			boundary := 0
+
+			if table.jsonPage == nil {
+				boundary = 1
+			}
+
+			pag, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+				Client:    &http.Client{},
+				URL:       server.URL + table.path + "?page=%d",
+				Boundary:  boundary,
+				JsonPage:  table.jsonPage,
+				ItemsPath: table.itemsPath,
+				Decoder:   table.decoder,
+			})
+
+			if err != nil {
+				t.Fatalf(err.Error())
+			}
+
+			res, err := pag.Get()
+
+			if err != nil {
+				t.Fatalf(err.Error())
+			}
+
+			if len(res) != table.pages {
+				t.Fatalf("Response collected not match, expected %d actual %d", table.pages, len(res))
+			}
+
+			for _, val := range res {
+
+				if len(val.Response.Items) != 2 {
+					t.Fatalf("Items extracted not match, expected %d actual %d", 2, len(val.Response.Items))
+				}
+
+				if id := fmt.Sprint(val.Response.Items[0].(map[string]interface{})["id"]); id != "1" {
+					t.Errorf("Item id not match, expected %s actual %s", "1", id)
+				}
+			}
+		})
+	}
+
+	// single repeated xml element is one item, json object is not an items array
+	singles := []struct {
+		name  string
+		path  string
+		items int
+	}{
+		{name: "single xml element", path: "/xml-single", items: 1},
+		{name: "json object", path: "/json-object", items: 0},
+	}
+
+	for _, single := range singles {
+		t.Run(single.name, func(t *testing.T) {
+
+			pag, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+				Client:    &http.Client{},
+				URL:       server.URL + single.path + "?page=%d",
+				Boundary:  1,
+				ItemsPath: "data.animal",
+			})
+
+			if err != nil {
+				t.Fatalf(err.Error())
+			}
+
+			res, err := pag.Get()
+
+			if err != nil {
+				t.Fatalf(err.Error())
+			}
+
+			if len(res[0].Response.Items) != single.items {
+				t.Errorf("Items extracted not match, expected %d actual %d", single.items, len(res[0].Response.Items))
+			}
+		})
+	}
+}
+
+func TestMsgPackMaliciousLength(t *testing.T) {
+
+	tables := []struct {
+		name string
+		data []byte
+	}{
+		{name: "map32 header", data: []byte{0xdf, 0x7f, 0xff, 0xff, 0xff}},
+		{name: "array32 header", data: []byte{0xdd, 0x7f, 0xff, 0xff, 0xff}},
+		{name: "map16 header with single entry", data: []byte{0xde, 0xff, 0xff, 0xa1, 0x61, 0x01}},
+	}
+
+	for _, table := range tables {
+		t.Run(table.name, func(t *testing.T) {
+
+			done := make(chan error, 1)
+
+			go func() {
+				_, err := MsgPackDecoder.Decode(table.data)
+				done <- err
+			}()
+
+			select {
+			case err := <-done:
+				if err == nil {
+					t.Errorf("Malicious length decoded without error")
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("Malicious length not rejected in time")
+			}
+		})
+	}
+}
+func TestXMLTokenListing(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		pages := map[string][]string{"": {"a.txt", "b.txt"}, "t1": {"c.txt", "d.txt"}, "t2": {"e.txt"}}
+		tokens := map[string]string{"": "t1", "t1": "t2"}
+
+		token := r.URL.Query().Get("continuation-token")
+
+		contents := ""
+		for _, key := range pages[token] {
+			contents += fmt.Sprintf("<Contents><Key>%s</Key><Size>10</Size></Contents>", key)
+		}
+
+		next, truncated := tokens[token]
+
+		w.Header().Set("Content-Type", "application/xml")
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?><ListBucketResult><Name>bucket</Name><IsTruncated>%t</IsTruncated>%s<NextContinuationToken>%s</NextContinuationToken></ListBucketResult>`, truncated, contents, next)
+	}))
+	defer server.Close()
+
+	items := []string{}
+
+	pag, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+		Client: &http.Client{},
+		URL:    server.URL + "/bucket?list-type=2",
+		Cursor: &XMLToken{TokenPath: "NextContinuationToken", TruncatedPath: "IsTruncated", ItemsPath: "Contents"},
+		ConcurrentBatch: func(batchResult []HttpInteraction) error {
+			for _, interaction := range batchResult {
+				for _, item := range interaction.Response.Items {
+					items = append(items, item.(map[string]interface{})["Key"].(string))
+				}
+			}
+			return nil
+		},
+	})
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	res, err := pag.Get()
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if len(res) != 3 {
+		t.Fatalf("Response collected not match, expected %d actual %d", 3, len(res))
+	}
+
+	if strings.Join(items, ",") != "a.txt,b.txt,c.txt,d.txt,e.txt" {
+		t.Errorf("Listed keys not match, actual %v", items)
+	}
+
+	if res[2].Request.Cursor != "t2" {
+		t.Errorf("Last continuation token not match, actual %s", res[2].Request.Cursor)
+	}
+
+	// list-type is kept first as it is written
+	if res[2].Request.HttpRequest.URL.RawQuery != "list-type=2&continuation-token=t2" {
+		t.Errorf("Query not match, actual %s", res[2].Request.HttpRequest.URL.RawQuery)
+	}
+}
+
+func TestFeedPaging(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		switch r.URL.Path {
+		case "/atom":
+			w.Header().Set("Content-Type", "application/atom+xml")
+			fmt.Fprint(w, `<feed xmlns="http://www.w3.org/2005/Atom"><title>changelog</title><link rel="self" href="/atom"/><link rel="prev-archive" href="/atom/2024"/><entry><id>3</id></entry><entry><id>4</id></entry></feed>`)
+		case "/atom/2024":
+			w.Header().Set("Content-Type", "application/atom+xml")
+			fmt.Fprint(w, `<feed xmlns="http://www.w3.org/2005/Atom"><link rel="current" href="/atom"/><entry><id>2</id></entry><entry><id>1</id></entry></feed>`)
+		case "/feeds/current.xml":
+			fmt.Fprint(w, `<feed xmlns="http://www.w3.org/2005/Atom"><link rel="prev-archive" href="archive/2.xml"/><entry><id>3</id></entry></feed>`)
+		case "/feeds/archive/2.xml":
+			fmt.Fprint(w, `<feed xmlns="http://www.w3.org/2005/Atom"><link rel="prev-archive" href="1.xml"/><entry><id>2</id></entry></feed>`)
+		case "/feeds/archive/1.xml":
+			fmt.Fprint(w, `<feed xmlns="http://www.w3.org/2005/Atom"><entry><id>1</id></entry></feed>`)
+		case "/rss":
+			next := ""
+			if r.URL.Query().Get("page") == "" {
+				next = `<atom:link rel="next" href="?page=2"/>`
+			}
+			w.Header().Set("Content-Type", "application/rss+xml")
+			fmt.Fprintf(w, `<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom"><channel><title>changelog</title><link>https://vendor.com</link>%s<item><guid>%s</guid></item></channel></rss>`, next, r.URL.Query().Get("page"))
+		}
+	}))
+	defer server.Close()
+
+	tables := []struct {
+		name  string
+		url   string
+		pages int
+		items int
+	}{
+		{name: "Atom archive", url: server.URL + "/atom", pages: 2, items: 4},
+		{name: "RSS next link", url: server.URL + "/rss", pages: 2, items: 2},
+		{name: "Relative link of archive document", url: server.URL + "/feeds/current.xml", pages: 3, items: 3},
+	}
+
+	for _, table := range tables {
+		t.Run(table.name, func(t *testing.T) {
+
+			pag, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+				Client: &http.Client{},
+				URL:    table.url,
+				Cursor: &Feed{},
+			})
+
+			if err != nil {
+				t.Fatalf(err.Error())
+			}
+
+			res, err := pag.Get()
+
+			if err != nil {
+				t.Fatalf(err.Error())
+			}
+
+			if len(res) != table.pages {
+				t.Fatalf("Response collected not match, expected %d actual %d", table.pages, len(res))
+			}
+
+			items := 0
+			for _, interaction := range res {
+
+				if interaction.Response.Error != nil {
+					t.Errorf("Page %s must not error, actual %s", interaction.Request.HttpRequest.URL, interaction.Response.Error.Error())
+				}
+
+				items += len(interaction.Response.Items)
+			}
+
+			if items != table.items {
+				t.Errorf("Entries collected not match, expected %d actual %d", table.items, items)
+			}
+		})
+	}
+}
+
+type listBooksService struct {
+	books []string
+	fail  string
+}
+
+// in-process stand-in of List RPC following page_size, page_token and next_page_token,
+// module stays free of gRPC dependency so generated client over bufconn is not used
+func (obj *listBooksService) ListBooks(ctx context.Context, pageSize int, pageToken string) ([]string, string, error) {
+
+	if pageToken != "" && pageToken == obj.fail {
+		return nil, "", errors.New("Unavailable")
+	}
+
+	offset, _ := strconv.Atoi(pageToken)
+
+	end := offset + pageSize
+	if end >= len(obj.books) {
+		return obj.books[offset:], "", nil
+	}
+
+	return obj.books[offset:end], strconv.Itoa(end), nil
+}
+
+func TestTransportPagination(t *testing.T) {
+
+	tables := []struct {
+		name     string
+		boundary int
+		fail     string
+		pages    int
+		items    int
+		failed   bool
+	}{
+		{name: "follow next page token", pages: 4, items: 10},
+		{name: "boundary as max pages", boundary: 2, pages: 2, items: 6},
+		{name: "call error recorded as page failure", fail: "6", pages: 3, items: 6, failed: true},
+	}
+
+	for _, table := range tables {
+		t.Run(table.name, func(t *testing.T) {
+
+			service := &listBooksService{fail: table.fail}
+			for i := 1; i <= 10; i++ {
+				service.books = append(service.books, fmt.Sprintf("books/%d", i))
+			}
+
+			pag, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+				Limit:    3,
+				Boundary: table.boundary,
+				Transport: TransportFunc(func(ctx context.Context, request *PageRequest) (*PageResponse, error) {
+
+					books, next, err := service.ListBooks(ctx, request.PageSize, request.PageToken)
+
+					if err != nil {
+						return nil, err
+					}
+
+					items := []interface{}{}
+					for _, book := range books {
+						items = append(items, book)
+					}
+
+					return &PageResponse{Items: items, NextPageToken: next}, nil
+				}),
+			})
+
+			if err != nil {
+				t.Fatalf(err.Error())
+			}
+
+			res, err := pag.Get()
+
+			if err != nil {
+				t.Fatalf(err.Error())
+			}
+
+			if len(res) != table.pages {
+				t.Fatalf("Response collected not match, expected %d actual %d", table.pages, len(res))
+			}
+
+			items := 0
+			for _, interaction := range res {
+				items += len(interaction.Response.Items)
+			}
+
+			if items != table.items {
+				t.Errorf("Items collected not match, expected %d actual %d", table.items, items)
+			}
+
+			if (res[len(res)-1].Response.Error != nil) != table.failed {
+				t.Errorf("Last page failure not match, actual %v", res[len(res)-1].Response.Error)
+			}
+		})
+	}
+
+	t.Run("Headers and plugins sent as call metadata", func(t *testing.T) {
+
+		pag, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+			Limit:    3,
+			Boundary: 2,
+			Headers:  Header{"X-Tenant": "a"},
+			Plugins:  []Plugin{&testHeaderPlugin{key: "Authorization", value: "Bearer secret"}},
+			Transport: TransportFunc(func(ctx context.Context, request *PageRequest) (*PageResponse, error) {
+
+				if request.Header.Get("X-Tenant") != "a" || request.Header.Get("Authorization") != "Bearer secret" {
+					return nil, errors.New("No Call Metadata Found")
+				}
+
+				return &PageResponse{Items: []interface{}{"books/1"}, NextPageToken: "next"}, nil
+			}),
+		})
+
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		res, err := pag.Get()
+
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		for _, val := range res {
+
+			if val.Response.Error != nil {
+				t.Fatalf("Page %d must not error, actual %s", val.Request.Pointer, val.Response.Error.Error())
+			}
+
+			if val.Request.HttpRequest == nil || val.Request.HttpRequest.URL.String() != TRANSPORT_URL {
+				t.Errorf("Request of page %d not found", val.Request.Pointer)
+			}
+		}
+	})
+}
+
+// set header of every page request
+type testHeaderPlugin struct {
+	BasePlugin
+	key   string
+	value string
+}
+
+func (obj *testHeaderPlugin) BeforeRequest(state *State, req *http.Request) error {
+
+	req.Header.Set(obj.key, obj.value)
+
+	return nil
+}
+
+func TestJsonRPCPagination(t *testing.T) {
+
+	type envelope struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+		Params struct {
+			Offset int    `json:"offset"`
+			Limit  int    `json:"limit"`
+			Cursor string `json:"cursor"`
+		} `json:"params"`
+	}
+
+	var roundTrips int64
+	var mutex sync.Mutex
+
+	answer := func(req envelope) string {
+
+		if req.Method == "list_blocks" && req.Params.Offset == 4 {
+			return fmt.Sprintf(`{"jsonrpc": "2.0", "id": %s, "error": {"code": -32000, "message": "pruned"}}`, req.ID)
+		}
+
+		if req.Method == "scan_blocks" {
+			// numeric cursor above 2^53 must be followed exactly
+			next := map[string]string{"": "9007199254740993", "9007199254740993": `"c"`, "c": "null"}[req.Params.Cursor]
+			return fmt.Sprintf(`{"jsonrpc": "2.0", "id": %s, "result": {"blocks": [1], "next": %s}}`, req.ID, next)
+		}
+
+		blocks := []string{}
+		for i := req.Params.Offset; i < req.Params.Offset+req.Params.Limit && i < 6; i++ {
+			blocks = append(blocks, strconv.Itoa(i))
+		}
+
+		return fmt.Sprintf(`{"jsonrpc": "2.0", "id": %s, "result": {"blocks": [%s]}}`, req.ID, strings.Join(blocks, ","))
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		mutex.Lock()
+		roundTrips++
+		mutex.Unlock()
+
+		var batch []envelope
+		var single envelope
+
+		decoder := json.NewDecoder(r.Body)
+
+		if r.URL.Query().Get("batch") != "" {
+
+			if err := decoder.Decode(&batch); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			answers := []string{}
+			for _, req := range batch {
+				answers = append(answers, answer(req))
+			}
+
+			fmt.Fprintf(w, "[%s]", strings.Join(answers, ","))
+			return
+		}
+
+		if err := decoder.Decode(&single); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		fmt.Fprint(w, answer(single))
+	}))
+	defer server.Close()
+
+	tables := []struct {
+		name       string
+		url        string
+		boundary   int
+		rpc        *JsonRPC
+		pages      int
+		failed     int
+		roundTrips int64
+	}{
+		{name: "single envelopes until empty result", url: server.URL, rpc: &JsonRPC{Method: "list_pages", Params: `{"offset": {offset}, "limit": {limit}}`}, pages: 4, roundTrips: 4},
+		{name: "batch arrays", url: server.URL + "?batch=1", boundary: 3, rpc: &JsonRPC{Method: "list_pages", Params: `{"offset": {offset}, "limit": {limit}}`, BatchSize: 3}, pages: 3, roundTrips: 1},
+		{name: "error object as page failure", url: server.URL + "?batch=1", boundary: 4, rpc: &JsonRPC{Method: "list_blocks", Params: `{"offset": {offset}, "limit": {limit}}`, BatchSize: 2}, pages: 4, failed: 1, roundTrips: 2},
+		{name: "follow result cursor", url: server.URL, rpc: &JsonRPC{Method: "scan_blocks", Params: `{"cursor": "{cursor}"}`, CursorPath: "next"}, pages: 3, roundTrips: 3},
+	}
+
+	for _, table := range tables {
+		t.Run(table.name, func(t *testing.T) {
+
+			roundTrips = 0
+
+			pag, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+				Client:     &http.Client{},
+				URL:        table.url,
+				Limit:      2,
+				Boundary:   table.boundary,
+				Concurrent: 2,
+				ItemsPath:  "blocks",
+				JsonRPC:    table.rpc,
+			})
+
+			if err != nil {
+				t.Fatalf(err.Error())
+			}
+
+			res, err := pag.Get()
+
+			if err != nil {
+				t.Fatalf(err.Error())
+			}
+
+			if len(res) != table.pages {
+				t.Fatalf("Response collected not match, expected %d actual %d", table.pages, len(res))
+			}
+
+			failed := 0
+			for _, interaction := range res {
+				if interaction.Response.Error != nil {
+					failed++
+				}
+			}
+
+			if failed != table.failed {
+				t.Errorf("Failed pages not match, expected %d actual %d", table.failed, failed)
+			}
+
+			if roundTrips != table.roundTrips {
+				t.Errorf("Round trips not match, expected %d actual %d", table.roundTrips, roundTrips)
+			}
+		})
+	}
+}
+
+func TestSessionLifecycle(t *testing.T) {
+
+	var mutex sync.Mutex
+	var closed []string
+	var keepAlive int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		var body struct {
+			ScrollID string `json:"scroll_id"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		switch {
+		case r.URL.Path == "/logs/_search":
+			fmt.Fprint(w, `{"_scroll_id": "s1", "hits": {"hits": [1, 2]}}`)
+		case r.Method == http.MethodDelete:
+			closed = append(closed, body.ScrollID)
+		case strings.HasPrefix(r.URL.Path, "/_search/scroll/keep-alive"):
+			keepAlive++
+			if strings.HasSuffix(r.URL.Path, "/expired") {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		case body.ScrollID == "s1":
+			fmt.Fprint(w, `{"_scroll_id": "s2", "hits": {"hits": [3, 4]}}`)
+		case body.ScrollID == "s2":
+			fmt.Fprint(w, `{"_scroll_id": "s3", "hits": {"hits": []}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	scroll := func(method string, path string) SessionRequest {
+		return func(ctx context.Context, cursor string) (*http.Request, error) {
+			return http.NewRequestWithContext(ctx, method, server.URL+path, strings.NewReader(`{"scroll_id": "`+cursor+`"}`))
+		}
+	}
+
+	tables := []struct {
+		name      string
+		callback  func(ctx context.Context, batchResult []HttpInteraction) error
+		cancel    bool
+		keepAlive int
+		expired   bool
+		pages     int
+		closed    string
+		err       bool
+	}{
+		{name: "Close latest cursor after last page", pages: 3, closed: "s3"},
+		{name: "Close cursor on callback error", callback: func(ctx context.Context, batchResult []HttpInteraction) error {
+			return errors.New("sink unavailable")
+		}, closed: "s2", err: true},
+		{name: "Close cursor on context cancellation", cancel: true, closed: "s2", err: true},
+		{name: "Keep cursor alive on slow batches", keepAlive: 1, callback: func(ctx context.Context, batchResult []HttpInteraction) error {
+			time.Sleep(1200 * time.Millisecond)
+			return nil
+		}, pages: 3, closed: "s3"},
+		{name: "Surface failed keep alive", keepAlive: 1, expired: true, callback: func(ctx context.Context, batchResult []HttpInteraction) error {
+			time.Sleep(1200 * time.Millisecond)
+			return nil
+		}, pages: 3, closed: "s3", err: true},
+	}
+
+	for _, table := range tables {
+		t.Run(table.name, func(t *testing.T) {
+
+			closed, keepAlive = nil, 0
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			callback := table.callback
+			if table.cancel {
+				callback = func(ctx context.Context, batchResult []HttpInteraction) error {
+					cancel()
+					return nil
+				}
+			}
+
+			keepAlivePath := "/_search/scroll/keep-alive"
+			if table.expired {
+				keepAlivePath += "/expired"
+			}
+
+			pag, err := NewPaginationAggregatorWithContext(ctx, &PaginationAggregatorConfig{
+				Client:      &http.Client{},
+				URL:         server.URL + "/_search/scroll",
+				Method:      http.MethodPost,
+				Body:        `{"scroll": "1m", "scroll_id": "{cursor}"}`,
+				Concurrent:  2,
+				ItemsPath:   "hits.hits",
+				Terminators: []Terminator{EmptyItems("hits.hits")},
+				Session: &Session{
+					Open:              scroll(http.MethodPost, "/logs/_search"),
+					CursorPath:        "_scroll_id",
+					CollectOpen:       true,
+					KeepAlive:         scroll(http.MethodPost, keepAlivePath),
+					KeepAliveInterval: table.keepAlive,
+					Close:             scroll(http.MethodDelete, "/_search/scroll"),
+				},
+				ConcurrentBatchWithContext: callback,
+			})
+
+			if err != nil {
+				t.Fatalf(err.Error())
+			}
+
+			res, err := pag.Get()
+
+			if (err != nil) != table.err {
+				t.Fatalf("Error not match, actual %v", err)
+			}
+
+			if len(res) != table.pages && (!table.err || table.expired) {
+				t.Fatalf("Response collected not match, expected %d actual %d", table.pages, len(res))
+			}
+
+			if len(closed) != 1 || closed[0] != table.closed {
+				t.Errorf("Closed cursor not match, expected %s actual %v", table.closed, closed)
+			}
+
+			if keepAlive < table.keepAlive {
+				t.Errorf("Keep alive requests not match, expected %d actual %d", table.keepAlive, keepAlive)
+			}
+		})
+	}
+}
+
+func TestTokenExpiryRecovery(t *testing.T) {
+
+	var mutex sync.Mutex
+	expired := map[string]bool{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		token := r.URL.Query().Get("continue")
+
+		if token == "t4" && (!expired[r.URL.Path] || r.URL.Path == "/always") {
+
+			expired[r.URL.Path] = true
+
+			if r.URL.Path == "/body" {
+				w.WriteHeader(http.StatusInternalServerError)
+				fmt.Fprint(w, `{"kind": "Status", "reason": "Expired"}`)
+				return
+			}
+
+			w.WriteHeader(http.StatusGone)
+			return
+		}
+
+		offset, _ := strconv.Atoi(strings.TrimPrefix(token, "t"))
+
+		// keys above 2^53 must be resumed exactly
+		base := int64(0)
+
+		if r.URL.Path == "/big" {
+			base = 9007199254740993
+		}
+
+		if after := r.URL.Query().Get("startAfter"); after != "" {
+			key, _ := strconv.ParseInt(after, 10, 64)
+			offset = int(key - base)
+		}
+
+		items := []string{}
+		for i := offset + 1; i <= offset+2 && i <= 6; i++ {
+			if r.URL.Path == "/twins" {
+				items = append(items, `{"name": "pod-twin", "id": 0}`)
+				continue
+			}
+			items = append(items, fmt.Sprintf(`{"name": "pod-%d", "id": %d}`, i, base+int64(i)))
+		}
+
+		if offset+2 < 6 {
+			w.Header().Set("X-Continue", fmt.Sprintf("t%d", offset+2))
+		}
+
+		fmt.Fprintf(w, `{"items": [%s]}`, strings.Join(items, ","))
+	}))
+	defer server.Close()
+
+	tables := []struct {
+		name   string
+		path   string
+		query  string
+		expiry *TokenExpiry
+		items  int
+		unique int
+		failed int
+	}{
+		{name: "Restart with deduplication", path: "/gone", expiry: &TokenExpiry{}, items: 6},
+		{name: "Identical items kept without expiry", path: "/twins", expiry: &TokenExpiry{}, items: 6, unique: 1},
+		{name: "Body predicate", path: "/body", expiry: &TokenExpiry{Expired: func(interaction HttpInteraction) bool {
+			return strings.Contains(interaction.Response.Data, `"reason": "Expired"`)
+		}, ItemKey: func(item interface{}) string {
+			return item.(map[string]interface{})["name"].(string)
+		}}, items: 6},
+		{name: "Resume from last key", path: "/gone", query: "?limit=2&labelSelector=app%3Dweb", expiry: &TokenExpiry{Recovery: RESUME_FROM_KEY, KeyPath: "id", KeyParam: "startAfter"}, items: 6},
+		{name: "Resume from big key", path: "/big", expiry: &TokenExpiry{Recovery: RESUME_FROM_KEY, KeyPath: "id", KeyParam: "startAfter"}, items: 6},
+		{name: "Give up after max recoveries", path: "/always", expiry: &TokenExpiry{MaxRecoveries: 2}, items: 4, failed: 1},
+	}
+
+	for _, table := range tables {
+		t.Run(table.name, func(t *testing.T) {
+
+			pag, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+				Client:      &http.Client{},
+				URL:         server.URL + table.path + table.query,
+				ItemsPath:   "items",
+				Cursor:      &HeaderCursor{ResponseHeader: "X-Continue", QueryParam: "continue"},
+				TokenExpiry: table.expiry,
+			})
+
+			if err != nil {
+				t.Fatalf(err.Error())
+			}
+
+			// every run recovers on its own, state of previous run is not reused
+			for run := 1; run <= 2; run++ {
+
+				mutex.Lock()
+				expired = map[string]bool{}
+				mutex.Unlock()
+
+				res, err := pag.Get()
+
+				if err != nil {
+					t.Fatalf(err.Error())
+				}
+
+				names := map[string]bool{}
+				items, failed := 0, 0
+
+				for _, interaction := range res {
+
+					if interaction.Response.Error != nil {
+						failed++
+					}
+
+					// resumed request keep params as they are written
+					if query := interaction.Request.HttpRequest.URL.RawQuery; strings.Contains(query, "startAfter") && !strings.HasPrefix("?"+query, table.query) {
+						t.Errorf("Resumed query not match, actual %s", query)
+					}
+
+					for _, item := range interaction.Response.Items {
+						names[item.(map[string]interface{})["name"].(string)] = true
+						items++
+					}
+				}
+
+				unique := table.unique
+				if unique == 0 {
+					unique = table.items
+				}
+
+				if items != table.items || len(names) != unique {
+					t.Errorf("Run %d items collected not match, expected %d actual %d (%d unique)", run, table.items, items, len(names))
+				}
+
+				if failed != table.failed {
+					t.Errorf("Run %d failed pages not match, expected %d actual %d", run, table.failed, failed)
+				}
+			}
+		})
+	}
+
+	t.Run("Config error token expiry with partitions", func(t *testing.T) {
+
+		_, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+			Client:      &http.Client{},
+			URL:         server.URL + "/gone?region={region}",
+			ItemsPath:   "items",
+			Cursor:      &HeaderCursor{ResponseHeader: "X-Continue", QueryParam: "continue"},
+			TokenExpiry: &TokenExpiry{},
+			Partitions:  []Partition{{Name: "eu", Params: map[string]string{"region": "eu"}}},
+		})
+
+		if err == nil {
+			t.Errorf("Error must not be null")
+		}
+	})
+}
+
+func TestExportJob(t *testing.T) {
+
+	var mutex sync.Mutex
+	polls := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		switch {
+		case r.Method == http.MethodPost:
+			fmt.Fprintf(w, `{"id": %s}`, r.URL.Query().Get("id"))
+		// job id above 2^53 must be polled exactly
+		case r.URL.Path == "/exports/9007199254740993":
+			polls++
+			if polls < 3 {
+				fmt.Fprint(w, `{"status": "running"}`)
+				return
+			}
+			fmt.Fprintf(w, `{"status": "completed", "urls": ["/chunks/1", "chunks/2?part=b", "%s/chunks/3"]}`, "http://"+r.Host)
+		case r.URL.Path == "/exports/7":
+			fmt.Fprint(w, `{"status": "failed"}`)
+		case r.URL.Path == "/exports/8":
+			fmt.Fprint(w, `{"status": "running"}`)
+		case strings.Contains(r.URL.Path, "/chunks/"):
+			fmt.Fprintf(w, `{"rows": ["%s-a", "%s-b"]}`, r.URL.Path, r.URL.Path)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tables := []struct {
+		name    string
+		id      string
+		chunks  int
+		err     bool
+		timeout time.Duration
+	}{
+		{name: "Poll until ready and fetch chunks", id: "9007199254740993", chunks: 3},
+		{name: "Failed job", id: "7", err: true},
+		{name: "Stop polling when context is done", id: "8", err: true, timeout: 100 * time.Millisecond},
+	}
+
+	for _, table := range tables {
+		t.Run(table.name, func(t *testing.T) {
+
+			polls = 0
+
+			ctx := context.Background()
+
+			if table.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, table.timeout)
+				defer cancel()
+			}
+
+			pag, err := NewPaginationAggregatorWithContext(ctx, &PaginationAggregatorConfig{
+				Client:     &http.Client{},
+				Concurrent: 3,
+				ItemsPath:  "rows",
+				ExportJob: &ExportJob{
+					Start: func(ctx context.Context, job string) (*http.Request, error) {
+						return http.NewRequestWithContext(ctx, http.MethodPost, server.URL+"/exports?id="+table.id, nil)
+					},
+					Poll: func(ctx context.Context, job string) (*http.Request, error) {
+						return http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/exports/"+job, nil)
+					},
+					JobPath:         "id",
+					StatusPath:      "status",
+					ReadyStatus:     "completed",
+					FailedStatuses:  []string{"failed"},
+					ChunksPath:      "urls",
+					PollInterval:    10 * time.Millisecond,
+					MaxPollInterval: 20 * time.Millisecond,
+				},
+			})
+
+			if err != nil {
+				t.Fatalf(err.Error())
+			}
+
+			res, err := pag.Get()
+
+			if (err != nil) != table.err {
+				t.Fatalf("Error not match, actual %v", err)
+			}
+
+			if table.timeout > 0 && !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("Error not match, expected %v actual %v", context.DeadlineExceeded, err)
+			}
+
+			if len(res) != table.chunks {
+				t.Fatalf("Response collected not match, expected %d actual %d", table.chunks, len(res))
+			}
+
+			for _, interaction := range res {
+				if interaction.Response.Error != nil || len(interaction.Response.Items) != 2 {
+					t.Errorf("Chunk %s not fetched, actual %v", interaction.Request.Key, interaction.Response.Error)
+				}
+			}
+		})
+	}
+}
+
+// login once, validate every page and record lifecycle phases
+type testLifecyclePlugin struct {
+	BasePlugin
+	loginURL  string
+	failLogin bool
+	mutex     sync.Mutex
+	phases    []string
+	readOnly  error
+	runErr    error
+	batches   int
+}
+
+func (obj *testLifecyclePlugin) record(phase string) {
+	obj.mutex.Lock()
+	defer obj.mutex.Unlock()
+	obj.phases = append(obj.phases, phase)
+}
+
+func (obj *testLifecyclePlugin) BeforeRun(state *State) error {
+
+	obj.record("before run")
+
+	if obj.failLogin {
+		return errors.New("Login Failed")
+	}
+
+	resp, err := http.Post(obj.loginURL, "application/json", nil)
+
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	return state.SetHeader("Authorization", "Bearer "+resp.Header.Get("X-Token"))
+}
+
+func (obj *testLifecyclePlugin) AfterResponse(state *State, interaction *HttpInteraction) error {
+
+	if strings.Contains(interaction.Response.Data, "invalid") {
+		return errors.New("Invalid Page")
+	}
+
+	return nil
+}
+
+func (obj *testLifecyclePlugin) AfterBatch(state *State, batch []HttpInteraction) error {
+
+	obj.record("after batch")
+	obj.readOnly = state.SetBoundary(100)
+	obj.batches = state.Batches()
+
+	return nil
+}
+
+func (obj *testLifecyclePlugin) AfterRun(state *State, result []HttpInteraction, err error) error {
+
+	obj.record("after run")
+	obj.runErr = err
+
+	return nil
+}
+
+func TestPluginLifecycle(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		if r.URL.Path == "/login" {
+			w.Header().Set("X-Token", "secret")
+			return
+		}
+
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.Header().Set("X-Total-Pages", "4")
+
+		if r.URL.Query().Get("page") == "3" {
+			fmt.Fprint(w, `{"status": "invalid"}`)
+			return
+		}
+
+		fmt.Fprintf(w, `{"page": %s}`, r.URL.Query().Get("page"))
+	}))
+	defer server.Close()
+
+	tables := []struct {
+		name      string
+		failLogin bool
+		pages     int
+		failed    int
+		phases    string
+	}{
+		{name: "Login, discover boundary and validate pages", pages: 4, failed: 1, phases: "before run,after batch,after batch,after run"},
+		{name: "After run on failed before run", failLogin: true, phases: "before run,after run"},
+	}
+
+	for _, table := range tables {
+		t.Run(table.name, func(t *testing.T) {
+
+			plugin := &testLifecyclePlugin{loginURL: server.URL + "/login", failLogin: table.failLogin}
+
+			pag, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+				Client:     &http.Client{},
+				URL:        server.URL + "?page={page}",
+				Concurrent: 2,
+				HeaderPage: headerTestTotalPages{},
+				Plugins:    []Plugin{plugin},
+			})
+
+			if err != nil {
+				t.Fatalf(err.Error())
+			}
+
+			res, err := pag.Get()
+
+			if (err != nil) != table.failLogin || plugin.runErr != err {
+				t.Fatalf("Error not match, actual %v after run %v", err, plugin.runErr)
+			}
+
+			if len(res) != table.pages {
+				t.Fatalf("Response collected not match, expected %d actual %d", table.pages, len(res))
+			}
+
+			failed := 0
+			for _, interaction := range res {
+				if interaction.Response.Error != nil {
+					failed++
+				}
+			}
+
+			if failed != table.failed {
+				t.Errorf("Failed pages not match, expected %d actual %d", table.failed, failed)
+			}
+
+			if strings.Join(plugin.phases, ",") != table.phases {
+				t.Errorf("Lifecycle phases not match, actual %v", plugin.phases)
+			}
+
+			if !table.failLogin && (plugin.readOnly == nil || plugin.batches != 2) {
+				t.Errorf("State view not match, set boundary error %v batches %d", plugin.readOnly, plugin.batches)
+			}
+		})
+	}
+}
+
+func TestBoundaryDiscoveredOnEveryRun(t *testing.T) {
+
+	totalPages := 2
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Total-Pages", strconv.Itoa(totalPages))
+		fmt.Fprintf(w, `{"page": %s}`, r.URL.Query().Get("page"))
+	}))
+	defer server.Close()
+
+	pag, err := NewPaginationAggregator(&PaginationAggregatorConfig{
+		Client:     &http.Client{},
+		URL:        server.URL + "/data?page=%d",
+		HeaderPage: headerTestTotalPages{},
+	})
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	for _, expected := range []int{2, 5} {
+
+		totalPages = expected
+
+		res, err := pag.Get()
+
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		if len(res) != expected {
+			t.Errorf("Response collected not match, expected %d actual %d", expected, len(res))
+		}
+	}
+}
+
+func TestConfigNotMutated(t *testing.T) {
+
+	open := func(ctx context.Context, cursor string) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodPost, "http://localhost/open", nil)
+	}
+
+	tables := []struct {
+		name   string
+		config *PaginationAggregatorConfig
+	}{
+		{name: "range", config: &PaginationAggregatorConfig{URL: "http://localhost/items", Range: &RangePagination{Size: 100}}},
+		{name: "xml token", config: &PaginationAggregatorConfig{URL: "http://localhost/bucket", Cursor: &XMLToken{TokenPath: "NextContinuationToken", ItemsPath: "Contents"}}},
+		{name: "transport", config: &PaginationAggregatorConfig{Transport: TransportFunc(func(ctx context.Context, request *PageRequest) (*PageResponse, error) {
+			return &PageResponse{}, nil
+		})}},
+		{name: "session", config: &PaginationAggregatorConfig{URL: "http://localhost/scroll", Session: &Session{Open: open, CursorPath: "_scroll_id"}}},
+		{name: "keys", config: &PaginationAggregatorConfig{URL: "http://localhost/items/{key}", Keys: []string{"a", "b"}}},
+		{name: "json rpc", config: &PaginationAggregatorConfig{URL: "http://localhost/rpc", Limit: 10, JsonRPC: &JsonRPC{Method: "list", Params: `{"offset": {offset}}`}}},
+	}
+
+	for _, table := range tables {
+		t.Run(table.name, func(t *testing.T) {
+
+			table.config.Client = &http.Client{}
+
+			// same config build several aggregators
+			for build := 0; build < 2; build++ {
+				if _, err := NewPaginationAggregator(table.config); err != nil {
+					t.Fatalf(err.Error())
+				}
+			}
+
+			config := table.config
+
+			if len(config.Terminators) != 0 || config.HeaderPage != nil || config.ItemsPath != "" || config.Decoder != nil || config.KeyGenerator != nil {
+				t.Errorf("Config of user is mutated")
+			}
+
+			if _, ok := config.Cursor.(*XMLToken); config.Cursor != nil && !ok {
+				t.Errorf("Cursor of user is replaced, actual %T", config.Cursor)
+			}
+
+			if config.JsonRPC != nil && *config.JsonRPC != (JsonRPC{Method: "list", Params: `{"offset": {offset}}`}) {
+				t.Errorf("JSON-RPC of user is mutated, actual %v", config.JsonRPC)
+			}
+		})
+	}
+}
+
 func TestMain(t *testing.M) {
 
 	port := 1234
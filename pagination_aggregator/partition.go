@@ -0,0 +1,206 @@
+package paginationaggregator
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Partition is independent slice of key space (ID range, date range, prefix, tenant) paginated with its own cursor chain
+type Partition struct {
+
+	// Name recorded on every request of this partition
+	Name string
+
+	// Values substituted into named URL and Body placeholders (e.g. {min_id}, {max_id})
+	Params map[string]string
+
+	// Checkpoint cursor to resume partition from, empty cursor start from first page
+	Cursor string
+}
+
+// PartitionProgress report fetched page of a partition, Cursor is checkpoint to resume the partition
+type PartitionProgress struct {
+	Partition string
+	Pages     int
+	Cursor    string
+	Done      bool
+	Error     error
+}
+
+type PartitionProgressCallback func(progress PartitionProgress)
+
+// fetched page of partition with its progress, progress is reported once page is collected
+type partitionPage struct {
+	interaction HttpInteraction
+	progress    PartitionProgress
+}
+
+// run independent cursor chain per partition concurrently and merge fetched pages into batches
+func (obj *PaginationAggregator) getByPartition() ([]HttpInteraction, error) {
+
+	var err error
+	var wg sync.WaitGroup
+
+	parent := obj.ctx
+
+	if parent == nil {
+		parent = context.Background()
+	}
+
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	channel := make(chan partitionPage)
+	semaphore := make(chan struct{}, obj.concurrent)
+
+	for _, partition := range obj.partitions {
+
+		wg.Add(1)
+
+		go func(partition Partition) {
+
+			defer wg.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			obj.runPartition(ctx, partition, channel)
+		}(partition)
+	}
+
+	go func() {
+		wg.Wait()
+		close(channel)
+	}()
+
+	var tmpBatch []HttpInteraction
+	var tmpProgress []PartitionProgress
+
+	for page := range channel {
+
+		if err != nil {
+			continue
+		}
+
+		tmpBatch = append(tmpBatch, page.interaction)
+		tmpProgress = append(tmpProgress, page.progress)
+
+		if len(tmpBatch) == obj.concurrent {
+
+			if err = obj.collectPartitions(tmpBatch, tmpProgress); err != nil {
+				cancel()
+			}
+
+			tmpBatch, tmpProgress = nil, nil
+		}
+	}
+
+	if err == nil && len(tmpBatch) > 0 {
+		err = obj.collectPartitions(tmpBatch, tmpProgress)
+	}
+
+	if err != nil {
+		return obj.finish(err)
+	}
+
+	if obj.ctx != nil && obj.ctx.Err() != nil {
+		return nil, obj.ctx.Err()
+	}
+
+	return obj.result, nil
+}
+
+// collect pages of partitions and checkpoint them only after they are passed to callback
+func (obj *PaginationAggregator) collectPartitions(tmpBatch []HttpInteraction, tmpProgress []PartitionProgress) error {
+
+	err := obj.collect(tmpBatch)
+
+	if err != nil && !errors.Is(err, errTerminated) {
+		return err
+	}
+
+	for _, progress := range tmpProgress {
+		obj.reportPartition(progress)
+	}
+
+	return err
+}
+
+func (obj *PaginationAggregator) runPartition(ctx context.Context, partition Partition, channel chan<- partitionPage) {
+
+	cursor := partition.Cursor
+	pages := 0
+
+	for pointer := obj.start; obj.boundary == 0 || pointer <= obj.boundary; pointer++ {
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		interaction := obj.fetchCursor(pointer, cursor, partition.Params)
+		interaction.Request.Partition = partition.Name
+
+		next, ok := cursor, false
+
+		if interaction.Response.Error == nil {
+			next, ok = obj.cursor.Next(&interaction)
+		}
+
+		pages++
+
+		done := !ok || next == "" || interaction.Response.Error != nil
+
+		progress := PartitionProgress{
+			Partition: partition.Name,
+			Pages:     pages,
+			Cursor:    next,
+			Done:      done,
+			Error:     interaction.Response.Error,
+		}
+
+		select {
+		case channel <- partitionPage{interaction: interaction, progress: progress}:
+		case <-ctx.Done():
+			return
+		}
+
+		if done {
+			return
+		}
+
+		cursor = next
+	}
+}
+
+func (obj *PaginationAggregator) reportPartition(progress PartitionProgress) {
+
+	if obj.partitionProgress == nil {
+		return
+	}
+
+	obj.mutex.Lock()
+	defer obj.mutex.Unlock()
+
+	obj.partitionProgress(progress)
+}
+
+func validatePartitions(partitions []Partition, cursor CursorStrategy) error {
+
+	if len(partitions) > 0 && cursor == nil {
+		return errors.New("No Cursor Strategy Found For Partitions")
+	}
+
+	names := map[string]bool{}
+
+	for _, partition := range partitions {
+
+		if names[partition.Name] {
+			return errors.New("Duplicate Partition Name " + partition.Name)
+		}
+
+		names[partition.Name] = true
+	}
+
+	return nil
+}
@@ -0,0 +1,296 @@
+package paginationaggregator
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Plugin hook into lifecycle of aggregation (e.g. login step, boundary discovery, response validator).
+// Embed BasePlugin to implement only phases you need. Request and response phases are called concurrently from every page request
+type Plugin interface {
+
+	// Called once before first page request, state can be configured only in this phase
+	BeforeRun(state *State) error
+
+	// Called before every page request is sent, error is recorded as failure of that page
+	BeforeRequest(state *State, req *http.Request) error
+
+	// Called after every page response, error is recorded as failure of that page
+	AfterResponse(state *State, interaction *HttpInteraction) error
+
+	// Called after every batch is collected and passed to callback, error stops aggregation
+	AfterBatch(state *State, batch []HttpInteraction) error
+
+	// Called once after aggregation ends even when it fails, err is error of aggregation
+	AfterRun(state *State, result []HttpInteraction, err error) error
+}
+
+// BasePlugin implement every lifecycle phase as no-op
+type BasePlugin struct {
+}
+
+func (obj BasePlugin) BeforeRun(state *State) error {
+	return nil
+}
+
+func (obj BasePlugin) BeforeRequest(state *State, req *http.Request) error {
+	return nil
+}
+
+func (obj BasePlugin) AfterResponse(state *State, interaction *HttpInteraction) error {
+	return nil
+}
+
+func (obj BasePlugin) AfterBatch(state *State, batch []HttpInteraction) error {
+	return nil
+}
+
+func (obj BasePlugin) AfterRun(state *State, result []HttpInteraction, err error) error {
+	return nil
+}
+
+// State is view of running aggregation given to plugins
+type State struct {
+	pag       *PaginationAggregator
+	mutex     sync.Mutex
+	running   bool
+	batches   int
+	responses int
+}
+
+// Context of aggregation, background context when aggregator is created without context
+func (obj *State) Context() context.Context {
+
+	obj.pag.mutex.Lock()
+	defer obj.pag.mutex.Unlock()
+
+	if obj.pag.ctx == nil {
+		return context.Background()
+	}
+
+	return obj.pag.ctx
+}
+
+func (obj *State) Start() int {
+	return obj.pag.start
+}
+
+// Boundary of pointer pagination, it may be changed by dynamic boundary while pages are fetched
+func (obj *State) Boundary() int {
+
+	obj.pag.mutex.Lock()
+	defer obj.pag.mutex.Unlock()
+
+	return obj.pag.boundary
+}
+
+func (obj *State) Limit() int {
+	return obj.pag.limit
+}
+
+func (obj *State) Concurrent() int {
+	return obj.pag.concurrent
+}
+
+// Number of collected batches
+func (obj *State) Batches() int {
+
+	obj.mutex.Lock()
+	defer obj.mutex.Unlock()
+
+	return obj.batches
+}
+
+// Number of page responses
+func (obj *State) Responses() int {
+
+	obj.mutex.Lock()
+	defer obj.mutex.Unlock()
+
+	return obj.responses
+}
+
+// Set boundary of pointer pagination, allowed only on BeforeRun
+func (obj *State) SetBoundary(boundary int) error {
+
+	if err := obj.writable(); err != nil {
+		return err
+	}
+
+	obj.pag.mutex.Lock()
+	obj.pag.boundary = boundary
+	obj.pag.mutex.Unlock()
+
+	return nil
+}
+
+// Set header of every page request (e.g. token of login step), allowed only on BeforeRun
+func (obj *State) SetHeader(key, value string) error {
+
+	if err := obj.writable(); err != nil {
+		return err
+	}
+
+	// configured headers are shared with config and child aggregations
+	headers := Header{}
+
+	for name, val := range obj.pag.headers {
+		headers[name] = val
+	}
+
+	headers[key] = value
+	obj.pag.headers = headers
+
+	return nil
+}
+
+// Set escaped URLs fetched concurrently as keys instead of configured URL (e.g. result chunks of export job), allowed only on BeforeRun
+func (obj *State) SetURLs(urls ...string) error {
+
+	if err := obj.writable(); err != nil {
+		return err
+	}
+
+	// URL is already escaped, it is rendered as is
+	obj.pag.template = newVerbatimTemplate("{" + PLACEHOLDER_KEY + "}")
+	obj.pag.keys = KeysOf(urls...)
+	obj.pag.method = http.MethodGet
+	obj.pag.body = nil
+	obj.pag.requestBuilder = nil
+
+	return nil
+}
+
+// Fetch single page of given pointer through configured client and request hooks
+func (obj *State) Fetch(pointer int) HttpInteraction {
+	return obj.send(func(ctx context.Context) (*http.Request, error) {
+		return obj.pag.newRequest(ctx, pointer, "")
+	}, pointer)
+}
+
+// Send request of given builder through configured client and request hooks, request is bound to context of aggregation and configured timeout
+func (obj *State) Send(builder func(ctx context.Context) (*http.Request, error)) HttpInteraction {
+	return obj.send(builder, 0)
+}
+
+// Wait given interval, it returns early with error when context of aggregation is done
+func (obj *State) Wait(interval time.Duration) error {
+
+	select {
+	case <-obj.Context().Done():
+		return obj.Context().Err()
+	case <-time.After(interval):
+		return nil
+	}
+}
+
+// Read boundary from page header or body with configured JsonPage or HeaderPage
+func (obj *State) ReadBoundary(header http.Header, data []byte) (int, error) {
+	return obj.pag.readBoundary(header, data)
+}
+
+func (obj *State) send(builder func(ctx context.Context) (*http.Request, error), page int) HttpInteraction {
+
+	ctx, cancel := context.WithTimeout(obj.Context(), time.Duration(obj.pag.timeout)*time.Second)
+	defer cancel()
+
+	req, err := builder(ctx)
+
+	return obj.pag.roundTrip(req, err, page)
+}
+
+func (obj *State) writable() error {
+
+	obj.mutex.Lock()
+	defer obj.mutex.Unlock()
+
+	if obj.running {
+		return errors.New("Aggregator State Is Read Only After Before Run")
+	}
+
+	return nil
+}
+
+func (obj *PaginationAggregator) beforeRun() error {
+
+	obj.state = &State{pag: obj}
+
+	for _, plugin := range obj.plugins {
+		if err := plugin.BeforeRun(obj.state); err != nil {
+			return err
+		}
+	}
+
+	obj.state.mutex.Lock()
+	obj.state.running = true
+	obj.state.mutex.Unlock()
+
+	return nil
+}
+
+func (obj *PaginationAggregator) beforeRequest(req *http.Request) error {
+
+	if obj.state == nil {
+		return nil
+	}
+
+	for _, plugin := range obj.plugins {
+		if err := plugin.BeforeRequest(obj.state, req); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (obj *PaginationAggregator) afterResponse(interaction *HttpInteraction) {
+
+	if obj.state == nil {
+		return
+	}
+
+	obj.state.mutex.Lock()
+	obj.state.responses++
+	obj.state.mutex.Unlock()
+
+	for _, plugin := range obj.plugins {
+		if err := plugin.AfterResponse(obj.state, interaction); err != nil {
+			interaction.Response.Error = err
+			return
+		}
+	}
+}
+
+func (obj *PaginationAggregator) afterBatch(tmpBatch []HttpInteraction) error {
+
+	if obj.state == nil {
+		return nil
+	}
+
+	obj.state.mutex.Lock()
+	obj.state.batches++
+	obj.state.mutex.Unlock()
+
+	for _, plugin := range obj.plugins {
+		if err := plugin.AfterBatch(obj.state, tmpBatch); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// every plugin ends its run, first error is returned
+func (obj *PaginationAggregator) afterRun(result []HttpInteraction, err error) ([]HttpInteraction, error) {
+
+	for _, plugin := range obj.plugins {
+		if pluginErr := plugin.AfterRun(obj.state, result, err); err == nil {
+			err = pluginErr
+		}
+	}
+
+	return result, err
+}
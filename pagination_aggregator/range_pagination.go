@@ -0,0 +1,73 @@
+package paginationaggregator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const DEFAULT_RANGE_UNIT = "items"
+
+// RangePagination request every page with Range header (e.g. Range: items=0-99),
+// read total from Content-Range response header and stop on 416 Range Not Satisfiable
+type RangePagination struct {
+
+	// Range unit, default "items"
+	Unit string
+
+	// Number of items requested on every page
+	Size int
+}
+
+// Range header value of given pointer, pointer start from 1
+func (obj *RangePagination) header(pointer int) string {
+
+	first := (pointer - 1) * obj.Size
+
+	return fmt.Sprintf("%s=%d-%d", obj.unit(), first, first+obj.Size-1)
+}
+
+// Read total from Content-Range (e.g. items 0-99/1234), unknown total (*) keep pagination going until 416
+func (obj *RangePagination) GetBoundaryFromHeader(header http.Header) int {
+
+	contentRange := header.Get("Content-Range")
+
+	idx := strings.LastIndex(contentRange, "/")
+
+	if idx < 0 {
+		return 0
+	}
+
+	if contentRange[idx+1:] == "*" {
+		return math.MaxInt32
+	}
+
+	total, err := strconv.Atoi(contentRange[idx+1:])
+
+	if err != nil {
+		return 0
+	}
+
+	return int(math.Ceil(float64(total) / float64(obj.Size)))
+}
+
+func (obj *RangePagination) unit() string {
+
+	if obj.Unit == "" {
+		return DEFAULT_RANGE_UNIT
+	}
+
+	return obj.Unit
+}
+
+func (obj *RangePagination) validate() error {
+
+	if obj.Size <= 0 {
+		return errors.New("No Range Size Found")
+	}
+
+	return nil
+}
@@ -0,0 +1,200 @@
+package paginationaggregator
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// SessionRequest build lifecycle request of cursor resource with its current cursor
+type SessionRequest func(ctx context.Context, cursor string) (*http.Request, error)
+
+// Session open cursor resource before pagination (e.g. Elasticsearch scroll or point in time, database cursor),
+// keep it alive while pages are fetched and close it when pagination ends
+type Session struct {
+
+	// Override this function to build request which opens cursor resource, cursor is empty
+	Open SessionRequest
+
+	// Dot separated path of cursor in open and page responses (e.g. _scroll_id), Cursor strategy is used when it is empty
+	CursorPath string
+
+	// Collect open response as first page (e.g. scroll search returns first hits)
+	CollectOpen bool
+
+	// Override this function to build keep alive request sent every KeepAliveInterval seconds
+	KeepAlive SessionRequest
+
+	// Keep alive interval in seconds
+	KeepAliveInterval int
+
+	// Override this function to build request which closes cursor resource, it is sent even on errors or context cancellation
+	Close SessionRequest
+}
+
+// sessionCursor read cursor from CursorPath of every page, cursor is substituted into {cursor} placeholder
+type sessionCursor struct {
+	path string
+}
+
+func (obj sessionCursor) Apply(req *http.Request, cursor string) error {
+	return nil
+}
+
+func (obj sessionCursor) Next(interaction *HttpInteraction) (string, bool) {
+
+	tree, err := interaction.Response.Decode()
+
+	if err != nil {
+		return "", false
+	}
+
+	value, ok := lookupPath(tree, obj.path)
+
+	if !ok {
+		return "", false
+	}
+
+	cursor, ok := value.(string)
+
+	return cursor, ok && cursor != ""
+}
+
+// open session, paginate from seeded cursor and close session whatever pagination ends
+func (obj *PaginationAggregator) getBySession() (result []HttpInteraction, err error) {
+
+	open := obj.sessionRoundTrip(obj.session.Open, "", obj.start)
+
+	if open.Response.Error != nil {
+		return nil, open.Response.Error
+	}
+
+	cursor, ok := obj.cursor.Next(&open)
+
+	obj.trackCursor(cursor)
+
+	defer func() {
+
+		closeErr := obj.closeSession()
+
+		if err == nil {
+			err = closeErr
+		}
+	}()
+
+	if !ok {
+		return nil, errors.New("No Session Cursor Found")
+	}
+
+	stop := obj.keepSessionAlive()
+
+	// keep alive ends before session is closed, its failure surfaces when pagination succeed
+	defer func() {
+
+		keepAliveErr := stop()
+
+		if err == nil {
+			err = keepAliveErr
+		}
+	}()
+
+	if !obj.session.CollectOpen {
+		return obj.getByCursorFrom(obj.start, cursor, nil)
+	}
+
+	return obj.getByCursorFrom(obj.start+1, cursor, []HttpInteraction{open})
+}
+
+// send keep alive every interval until returned stop is called, stop wait for running keep alive and return its last failure
+func (obj *PaginationAggregator) keepSessionAlive() func() error {
+
+	if obj.session.KeepAlive == nil || obj.session.KeepAliveInterval <= 0 {
+		return func() error { return nil }
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+
+	var lastErr error
+
+	go func() {
+
+		defer close(done)
+
+		ticker := time.NewTicker(time.Duration(obj.session.KeepAliveInterval) * time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if err := obj.sessionRoundTrip(obj.session.KeepAlive, obj.currentCursor(), 0).Response.Error; err != nil {
+					lastErr = err
+				}
+			}
+		}
+	}()
+
+	return func() error {
+
+		close(stop)
+		<-done
+
+		return lastErr
+	}
+}
+
+func (obj *PaginationAggregator) closeSession() error {
+
+	if obj.session.Close == nil {
+		return nil
+	}
+
+	return obj.sessionRoundTrip(obj.session.Close, obj.currentCursor(), 0).Response.Error
+}
+
+// send lifecycle request detached from aggregator context, so close request is still sent after cancellation
+func (obj *PaginationAggregator) sessionRoundTrip(builder SessionRequest, cursor string, page int) HttpInteraction {
+
+	requestCtx, cancel := context.WithTimeout(context.Background(), time.Duration(obj.timeout)*time.Second)
+	defer cancel()
+
+	req, err := builder(requestCtx, cursor)
+
+	return obj.roundTrip(req, err, page)
+}
+
+func (obj *PaginationAggregator) trackCursor(cursor string) {
+
+	obj.mutex.Lock()
+	defer obj.mutex.Unlock()
+
+	obj.sessionCursor = cursor
+}
+
+func (obj *PaginationAggregator) currentCursor() string {
+
+	obj.mutex.Lock()
+	defer obj.mutex.Unlock()
+
+	return obj.sessionCursor
+}
+
+func validateSession(session *Session, cursor CursorStrategy) error {
+
+	if session == nil {
+		return nil
+	}
+
+	if session.Open == nil {
+		return errors.New("No Session Open Request Found")
+	}
+
+	if session.CursorPath == "" && cursor == nil {
+		return errors.New("No Session Cursor Path Or Cursor Found")
+	}
+
+	return nil
+}
@@ -0,0 +1,243 @@
+package paginationaggregator
+
+import (
+	"crypto/sha256"
+	"errors"
+	"sort"
+	"sync"
+)
+
+var errTerminated = errors.New("Aggregation Terminated")
+
+// Terminator decide whether aggregation should stop after given page, pages after terminated page are discarded
+type Terminator interface {
+	Terminate(interaction HttpInteraction) bool
+}
+
+type TerminatorFunc func(interaction HttpInteraction) bool
+
+func (fn TerminatorFunc) Terminate(interaction HttpInteraction) bool {
+	return fn(interaction)
+}
+
+// Stop when array at json path is empty or missing
+func EmptyItems(path string) Terminator {
+	return TerminatorFunc(func(interaction HttpInteraction) bool {
+
+		if interaction.Response.Error != nil {
+			return false
+		}
+
+		tree, err := interaction.Response.Decode()
+
+		if err != nil {
+			return false
+		}
+
+		items, _ := interaction.Response.lookupItems(tree, path)
+
+		return len(items) == 0
+	})
+}
+
+// Stop when response return one of http status codes (e.g. 204, 404)
+func StatusCodes(codes ...int) Terminator {
+	return TerminatorFunc(func(interaction HttpInteraction) bool {
+
+		for _, code := range codes {
+			if interaction.Response.Status == code {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+// Stop when number of collected items at json path reach max, items beyond max are trimmed from Response.Items
+func MaxItems(max int, path string) Terminator {
+	return &maxItems{max: max, path: path}
+}
+
+// Stop when number of fetched pages reach max
+func MaxPages(max int) Terminator {
+	return newStatefulTerminator(func() TerminatorFunc {
+
+		pages := 0
+
+		return func(interaction HttpInteraction) bool {
+			pages++
+			return pages >= max
+		}
+	})
+}
+
+// Stop when size of fetched response data reach max bytes
+func MaxBytes(max int) Terminator {
+	return newStatefulTerminator(func() TerminatorFunc {
+
+		size := 0
+
+		return func(interaction HttpInteraction) bool {
+			size += len(interaction.Response.Data)
+			return size >= max
+		}
+	})
+}
+
+// Stop when any items at json path match predicate (e.g. created_at older than X)
+func StopWhen(path string, predicate func(item interface{}) bool) Terminator {
+	return TerminatorFunc(func(interaction HttpInteraction) bool {
+
+		tree, err := interaction.Response.Decode()
+
+		if err != nil {
+			return false
+		}
+
+		items, _ := interaction.Response.lookupItems(tree, path)
+
+		for _, item := range items {
+			if predicate(item) {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+// Stop when api keep returning same response as previous page (e.g. last page returned forever)
+func RepeatedPage() Terminator {
+	return newStatefulTerminator(func() TerminatorFunc {
+
+		var previous [sha256.Size]byte
+		var seen bool
+
+		return func(interaction HttpInteraction) bool {
+
+			if interaction.Response.Error != nil {
+				return false
+			}
+
+			current := sha256.Sum256([]byte(interaction.Response.Data))
+			repeated := seen && current == previous
+
+			previous, seen = current, true
+
+			return repeated
+		}
+	})
+}
+
+// runTerminator is terminator which counts pages of single run, every run of aggregator use its own fresh state
+type runTerminator interface {
+	Terminator
+	newRun() Terminator
+}
+
+// statefulTerminator create fresh terminator state for every run, state used outside of aggregator is created once
+type statefulTerminator struct {
+	fresh  func() TerminatorFunc
+	once   sync.Once
+	shared TerminatorFunc
+}
+
+func newStatefulTerminator(fresh func() TerminatorFunc) *statefulTerminator {
+	return &statefulTerminator{fresh: fresh}
+}
+
+func (obj *statefulTerminator) Terminate(interaction HttpInteraction) bool {
+
+	obj.once.Do(func() {
+		obj.shared = obj.fresh()
+	})
+
+	return obj.shared(interaction)
+}
+
+func (obj *statefulTerminator) newRun() Terminator {
+	return obj.fresh()
+}
+
+type maxItems struct {
+	max       int
+	path      string
+	collected int
+}
+
+func (obj *maxItems) Terminate(interaction HttpInteraction) bool {
+
+	if tree, err := interaction.Response.Decode(); err == nil {
+
+		items, _ := interaction.Response.lookupItems(tree, obj.path)
+
+		if kept := obj.max - obj.collected; len(items) > kept {
+			interaction.Response.Items = items[:kept]
+		}
+
+		obj.collected += len(items)
+	}
+
+	return obj.collected >= obj.max
+}
+
+func (obj *maxItems) newRun() Terminator {
+	return &maxItems{max: obj.max, path: obj.path}
+}
+
+// fresh terminators of single run, terminators given by config are shared between runs and aggregators
+func newRunTerminators(terminators []Terminator) []Terminator {
+
+	var fresh []Terminator
+
+	for _, terminator := range terminators {
+
+		if stateful, ok := terminator.(runTerminator); ok {
+			terminator = stateful.newRun()
+		}
+
+		fresh = append(fresh, terminator)
+	}
+
+	return fresh
+}
+
+// evaluate terminators in pointer order and discard pages after terminated page
+func (obj *PaginationAggregator) terminate(tmpBatch *[]HttpInteraction) bool {
+
+	if len(obj.runTerminators) == 0 {
+		return false
+	}
+
+	ordered := make([]HttpInteraction, len(*tmpBatch))
+	copy(ordered, *tmpBatch)
+
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Request.Pointer < ordered[j].Request.Pointer
+	})
+
+	for _, interaction := range ordered {
+
+		for _, terminator := range obj.runTerminators {
+
+			if !terminator.Terminate(interaction) {
+				continue
+			}
+
+			var kept []HttpInteraction
+
+			for _, val := range *tmpBatch {
+				if val.Request.Pointer <= interaction.Request.Pointer {
+					kept = append(kept, val)
+				}
+			}
+
+			*tmpBatch = kept
+
+			return true
+		}
+	}
+
+	return false
+}
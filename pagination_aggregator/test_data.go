@@ -2,6 +2,7 @@ package paginationaggregator
 
 import (
 	"net/http"
+	"strconv"
 )
 
 type supplyData interface {
@@ -9,22 +10,30 @@ type supplyData interface {
 }
 
 type animal struct {
-	Id     int    `json:"id"`
-	Animal string `json:"animal"`
+	Id     int    `json:"id" xml:"id"`
+	Animal string `json:"animal" xml:"animal"`
 }
 
 // json response with page and size/per page
 type jsonTestStructPagePerPage struct {
-	StatusCode int      `json:"-"`
-	Page       int      `json:"page"`
-	TotalPages int      `json:"total_pages"`
-	Animals    []animal `json:"data"`
+	StatusCode int      `json:"-" xml:"-"`
+	Page       int      `json:"page" xml:"page"`
+	TotalPages int      `json:"total_pages" xml:"total_pages"`
+	Animals    []animal `json:"data" xml:"data>animal"`
 }
 
 func (obj *jsonTestStructPagePerPage) GetBoundary() int {
 	return obj.TotalPages
 }
 
+// header response with total pages
+type headerTestTotalPages struct{}
+
+func (obj headerTestTotalPages) GetBoundaryFromHeader(header http.Header) int {
+	total, _ := strconv.Atoi(header.Get("X-Total-Pages"))
+	return total
+}
+
 type metaTestData struct {
 	NumberOfResponse  int
 	NumberOfData      int
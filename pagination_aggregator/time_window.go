@@ -0,0 +1,200 @@
+package paginationaggregator
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	PLACEHOLDER_START_TIME = "start_time"
+	PLACEHOLDER_END_TIME   = "end_time"
+
+	DEFAULT_MIN_WINDOW = time.Second
+)
+
+// Window is time range requested on single page, start is inclusive and end is exclusive
+type Window struct {
+	Start time.Time
+	End   time.Time
+
+	// Window still hit result cap at MinWindow, api may have omitted items of this window
+	Truncated bool
+}
+
+// TimeWindow walk date range in fixed windows substituted into {start_time} and {end_time} placeholders,
+// window which hit api result cap is recursively split into smaller windows
+type TimeWindow struct {
+
+	// Start of date range
+	From time.Time
+
+	// End of date range
+	To time.Time
+
+	// Size of every window (e.g. time.Hour, 24 * time.Hour)
+	Window time.Duration
+
+	// Time format of placeholders, default time.RFC3339
+	Layout string
+
+	// Max number of items api returns on single window, 0 disable window splitting
+	Cap int
+
+	// Dot separated path of items array in response, empty path means response is an array
+	ItemsPath string
+
+	// Smallest window size to split into, default 1 second
+	MinWindow time.Duration
+}
+
+func (obj *TimeWindow) windows() []Window {
+
+	var windows []Window
+
+	for start := obj.From; start.Before(obj.To); start = start.Add(obj.Window) {
+
+		end := start.Add(obj.Window)
+
+		if end.After(obj.To) {
+			end = obj.To
+		}
+
+		windows = append(windows, Window{Start: start, End: end})
+	}
+
+	return windows
+}
+
+// split window into halves when its response hit result cap, window at MinWindow which hit result cap is not split
+func (obj *TimeWindow) split(window Window, interaction HttpInteraction) (halves []Window, capped bool) {
+
+	if obj.Cap == 0 || interaction.Response.Error != nil {
+		return nil, false
+	}
+
+	tree, err := interaction.Response.Decode()
+
+	if err != nil {
+		return nil, false
+	}
+
+	if items, _ := interaction.Response.lookupItems(tree, obj.ItemsPath); len(items) < obj.Cap {
+		return nil, false
+	}
+
+	size := window.End.Sub(window.Start)
+
+	if size <= obj.minWindow() {
+		return nil, true
+	}
+
+	middle := window.Start.Add(size / 2)
+
+	return []Window{{Start: window.Start, End: middle}, {Start: middle, End: window.End}}, true
+}
+
+func (obj *TimeWindow) values(window Window) map[string]string {
+
+	layout := obj.Layout
+
+	if layout == "" {
+		layout = time.RFC3339
+	}
+
+	return map[string]string{
+		PLACEHOLDER_START_TIME: window.Start.Format(layout),
+		PLACEHOLDER_END_TIME:   window.End.Format(layout),
+	}
+}
+
+func (obj *TimeWindow) minWindow() time.Duration {
+
+	if obj.MinWindow == 0 {
+		return DEFAULT_MIN_WINDOW
+	}
+
+	return obj.MinWindow
+}
+
+func (obj *TimeWindow) validate() error {
+
+	if !obj.From.Before(obj.To) {
+		return errors.New("Time Window From Must Be Before To")
+	}
+
+	if obj.Window <= 0 {
+		return errors.New("No Time Window Size Found")
+	}
+
+	return nil
+}
+
+// fetch every window concurrently in batches, windows which hit result cap are replaced by its halves
+func (obj *PaginationAggregator) getByTimeWindow() ([]HttpInteraction, error) {
+
+	queue := obj.timeWindow.windows()
+	pointer := obj.start
+
+	for len(queue) > 0 {
+
+		size := obj.concurrent
+
+		if len(queue) < size {
+			size = len(queue)
+		}
+
+		windows := queue[:size]
+		queue = queue[size:]
+
+		var splitted []Window
+		var tmpBatch []HttpInteraction
+
+		for idx, interaction := range obj.fetchWindows(pointer, windows) {
+
+			halves, capped := obj.timeWindow.split(windows[idx], interaction)
+
+			if len(halves) > 0 {
+				splitted = append(splitted, halves...)
+				continue
+			}
+
+			interaction.Request.Window.Truncated = capped
+
+			tmpBatch = append(tmpBatch, interaction)
+		}
+
+		pointer += size
+		queue = append(splitted, queue...)
+
+		if err := obj.collect(tmpBatch); err != nil {
+			return obj.finish(err)
+		}
+
+		if obj.ctx != nil && obj.ctx.Err() != nil {
+			return nil, obj.ctx.Err()
+		}
+
+		if len(queue) > 0 {
+			time.Sleep(time.Duration(obj.delayBetweenBatch) * time.Second)
+		}
+	}
+
+	return obj.result, nil
+}
+
+func (obj *PaginationAggregator) fetchWindows(pointer int, windows []Window) []HttpInteraction {
+
+	var values []map[string]string
+
+	for _, window := range windows {
+		values = append(values, obj.timeWindow.values(window))
+	}
+
+	interactions := obj.fetchValues(pointer, values)
+
+	for idx := range interactions {
+		interactions[idx].Request.Window = &windows[idx]
+	}
+
+	return interactions
+}
@@ -0,0 +1,235 @@
+package paginationaggregator
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+type ExpiryRecovery int
+
+const (
+	// restart listing from first page and skip items which are already collected
+	RESTART_LISTING ExpiryRecovery = iota
+
+	// resume listing after key of last collected item with fallback keyset param
+	RESUME_FROM_KEY
+)
+
+const DEFAULT_MAX_RECOVERIES = 3
+
+// TokenExpiry detect expired continuation token (e.g. Kubernetes 410 Gone) and recover listing instead of recording failed page
+type TokenExpiry struct {
+
+	// Status codes of expired token, default 410
+	StatusCodes []int
+
+	// Override this function to detect expired token from response, error response body is kept on Response.Data
+	Expired func(interaction HttpInteraction) bool
+
+	// Recovery strategy, default RESTART_LISTING
+	Recovery ExpiryRecovery
+
+	// Override this function to identify item for RESTART_LISTING deduplication, default whole item.
+	// Only items replayed after restart are removed from Response.Items, Response.Data keeps page as it is served
+	ItemKey func(item interface{}) string
+
+	// Dot separated path of key inside item resumed from on RESUME_FROM_KEY
+	KeyPath string
+
+	// Query param which carries last key on RESUME_FROM_KEY (e.g. startAfter)
+	KeyParam string
+
+	// Max recoveries of single listing, default 3
+	MaxRecoveries int
+}
+
+// recovery progress of single listing, it is reset on every run
+type tokenExpiryState struct {
+	recoveries int
+	lastKey    string
+	resuming   bool
+
+	// hash of collected items counted by occurrence
+	seen map[[sha256.Size]byte]int
+
+	// collected items which are expected to be replayed after restart
+	replay map[[sha256.Size]byte]int
+}
+
+// expiryCursor pass last key on first request of resumed listing, other requests are applied by wrapped strategy
+type expiryCursor struct {
+	CursorStrategy
+	expiry *TokenExpiry
+	state  *tokenExpiryState
+}
+
+func (obj *expiryCursor) Apply(req *http.Request, cursor string) error {
+
+	if cursor == "" && obj.state.resuming {
+		setQueryParam(req.URL, obj.expiry.KeyParam, obj.state.lastKey)
+	}
+
+	return obj.CursorStrategy.Apply(req, cursor)
+}
+
+// recover expired page, it returns true when listing is restarted or resumed and expired page must be dropped
+func (obj *PaginationAggregator) recoverExpiredToken(interaction HttpInteraction) bool {
+
+	if obj.tokenExpiry == nil || !obj.tokenExpiry.expired(interaction) {
+		return false
+	}
+
+	if obj.expiryState.recoveries >= obj.tokenExpiry.maxRecoveries() {
+		return false
+	}
+
+	if obj.tokenExpiry.Recovery == RESUME_FROM_KEY && obj.expiryState.lastKey == "" {
+		return false
+	}
+
+	obj.expiryState.recoveries++
+	obj.expiryState.resuming = obj.tokenExpiry.Recovery == RESUME_FROM_KEY
+
+	if obj.tokenExpiry.Recovery == RESTART_LISTING {
+
+		obj.expiryState.replay = map[[sha256.Size]byte]int{}
+
+		for key, count := range obj.expiryState.seen {
+			obj.expiryState.replay[key] = count
+		}
+	}
+
+	return true
+}
+
+// track items of fetched page, items replayed after restart are removed from page
+func (obj *PaginationAggregator) trackExpiryItems(interaction *HttpInteraction) {
+
+	if obj.tokenExpiry == nil || interaction.Response.Error != nil {
+		return
+	}
+
+	items := interaction.Response.Items
+
+	if obj.tokenExpiry.Recovery == RESTART_LISTING {
+
+		if obj.expiryState.seen == nil {
+			obj.expiryState.seen = map[[sha256.Size]byte]int{}
+		}
+
+		var fresh []interface{}
+
+		for _, item := range items {
+
+			key := sha256.Sum256([]byte(obj.tokenExpiry.itemKey(item)))
+
+			// identical items of listing are kept, only occurrences collected before restart are dropped
+			if obj.expiryState.replay[key] > 0 {
+				obj.expiryState.replay[key]--
+				continue
+			}
+
+			obj.expiryState.seen[key]++
+			fresh = append(fresh, item)
+		}
+
+		if len(fresh) < len(items) {
+			interaction.Response.Items = fresh
+		}
+	}
+
+	if obj.tokenExpiry.Recovery == RESUME_FROM_KEY && len(items) > 0 {
+		if key, ok := obj.lastItemKey(interaction.Response, items); ok {
+			obj.expiryState.lastKey = key
+		}
+	}
+}
+
+// key of last item, page is decoded again with json numbers so big integer keys are resumed exactly
+func (obj *PaginationAggregator) lastItemKey(response *Response, items []interface{}) (string, bool) {
+
+	last := items[len(items)-1]
+
+	if tree, err := response.decodeExact(); err == nil {
+		if exact, ok := response.lookupItems(tree, obj.itemsPath); ok && len(exact) == len(items) {
+			last = exact[len(exact)-1]
+		}
+	}
+
+	key, ok := lookupPath(last, obj.tokenExpiry.KeyPath)
+
+	if !ok {
+		return "", false
+	}
+
+	return stringify(key), true
+}
+
+func (obj *TokenExpiry) expired(interaction HttpInteraction) bool {
+
+	if obj.Expired != nil && obj.Expired(interaction) {
+		return true
+	}
+
+	codes := obj.StatusCodes
+
+	if len(codes) == 0 {
+		codes = []int{http.StatusGone}
+	}
+
+	for _, code := range codes {
+		if interaction.Response.Status == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (obj *TokenExpiry) itemKey(item interface{}) string {
+
+	if obj.ItemKey != nil {
+		return obj.ItemKey(item)
+	}
+
+	key, _ := json.Marshal(item)
+
+	return string(key)
+}
+
+func (obj *TokenExpiry) maxRecoveries() int {
+
+	if obj.MaxRecoveries == 0 {
+		return DEFAULT_MAX_RECOVERIES
+	}
+
+	return obj.MaxRecoveries
+}
+
+func validateTokenExpiry(expiry *TokenExpiry, cursor CursorStrategy, itemsPath string, partitions []Partition) error {
+
+	if expiry == nil {
+		return nil
+	}
+
+	// recovery state belongs to single listing, partition chains are not recovered
+	if len(partitions) > 0 {
+		return errors.New("Token Expiry Is Not Supported With Partitions")
+	}
+
+	if cursor == nil {
+		return errors.New("No Cursor Found To Recover Expired Token")
+	}
+
+	if itemsPath == "" {
+		return errors.New("No Items Path Found To Recover Expired Token")
+	}
+
+	if expiry.Recovery == RESUME_FROM_KEY && (expiry.KeyPath == "" || expiry.KeyParam == "") {
+		return errors.New("No Key Path Or Key Param Found To Resume Expired Token")
+	}
+
+	return nil
+}
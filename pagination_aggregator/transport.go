@@ -0,0 +1,160 @@
+package paginationaggregator
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// Transport fetch single page over non http protocol (e.g. gRPC List RPC with page_size, page_token and next_page_token).
+// Module does not depend on gRPC, generated client of the service is called by implementation
+type Transport interface {
+	Call(ctx context.Context, request *PageRequest) (*PageResponse, error)
+}
+
+// TransportFunc adapt ordinary function as Transport
+type TransportFunc func(ctx context.Context, request *PageRequest) (*PageResponse, error)
+
+func (obj TransportFunc) Call(ctx context.Context, request *PageRequest) (*PageResponse, error) {
+	return obj(ctx, request)
+}
+
+type PageRequest struct {
+
+	// Page size taken from Limit
+	PageSize int
+
+	// Token of requested page, empty on first page
+	PageToken string
+
+	// Sequence number of requested page
+	Pointer int
+
+	// Params of aggregator merged with partition params
+	Params map[string]string
+
+	// Headers of aggregator and plugins (e.g. authorization), sent as call metadata
+	Header http.Header
+}
+
+type PageResponse struct {
+
+	// Items of fetched page
+	Items []interface{}
+
+	// Token of next page, empty token stops pagination
+	NextPageToken string
+
+	// Optional encoded page (e.g. protojson), decoded for ItemsPath and JsonPage when Items is empty
+	Data string
+
+	// Optional response metadata
+	Header http.Header
+}
+
+// url of synthetic http request which carries transport page through plugins
+const TRANSPORT_URL = "transport://page"
+
+// pageToken follow next page token returned by transport
+type pageToken struct{}
+
+func (obj pageToken) Apply(req *http.Request, cursor string) error {
+	return nil
+}
+
+func (obj pageToken) Next(interaction *HttpInteraction) (string, bool) {
+
+	token := interaction.Response.nextPageToken
+
+	return token, token != ""
+}
+
+// call transport within shared concurrency budget, call error is recorded as failure of that page
+func (obj *PaginationAggregator) call(ctx context.Context, page int, cursor string, params map[string]string) HttpInteraction {
+
+	if obj.limiter != nil {
+		obj.limiter <- struct{}{}
+		defer func() { <-obj.limiter }()
+	}
+
+	values := map[string]string{}
+
+	for key, value := range obj.params {
+		values[key] = value
+	}
+
+	for key, value := range params {
+		values[key] = value
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, TRANSPORT_URL, nil)
+
+	if err == nil {
+
+		for key, value := range obj.headers {
+			req.Header.Set(key, value)
+		}
+
+		err = obj.beforeRequest(req)
+	}
+
+	var resp *PageResponse
+
+	if err == nil {
+
+		resp, err = obj.transport.Call(ctx, &PageRequest{
+			PageSize:  obj.limit,
+			PageToken: cursor,
+			Pointer:   page,
+			Params:    values,
+			Header:    req.Header,
+		})
+	}
+
+	if err == nil && resp == nil {
+		err = errors.New("No Page Response Found")
+	}
+
+	if err != nil {
+		interaction := obj.failure(req, page, http.StatusInternalServerError, err, nil)
+		interaction.Request.Params = params
+		obj.afterResponse(&interaction)
+		return interaction
+	}
+
+	response := &Response{
+		Status:        http.StatusOK,
+		StatusText:    http.StatusText(http.StatusOK),
+		Data:          resp.Data,
+		Header:        resp.Header,
+		Items:         resp.Items,
+		decoder:       obj.decoder,
+		nextPageToken: resp.NextPageToken,
+	}
+
+	if response.Items == nil {
+		response.Items = obj.extractItems(response)
+	}
+
+	interaction := HttpInteraction{
+		Request: &Request{
+			HttpRequest: req,
+			Pointer:     page,
+			Params:      params,
+		},
+		Response: response,
+	}
+
+	obj.afterResponse(&interaction)
+
+	return interaction
+}
+
+func validateTransport(transport Transport, cursor CursorStrategy) error {
+
+	if _, ok := cursor.(pageToken); transport != nil && cursor != nil && !ok {
+		return errors.New("Cursor Is Not Supported With Transport")
+	}
+
+	return nil
+}
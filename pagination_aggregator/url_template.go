@@ -0,0 +1,292 @@
+package paginationaggregator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	PLACEHOLDER_PAGE    = "page"
+	PLACEHOLDER_POINTER = "pointer"
+	PLACEHOLDER_OFFSET  = "offset"
+	PLACEHOLDER_LIMIT   = "limit"
+	PLACEHOLDER_CURSOR  = "cursor"
+)
+
+var placeholderPattern = regexp.MustCompile(`\{([a-zA-Z_][a-zA-Z0-9_.]*)\}`)
+
+// placeholders filled by aggregator, params must not override them
+var reservedPlaceholders = map[string]bool{
+	PLACEHOLDER_PAGE:       true,
+	PLACEHOLDER_POINTER:    true,
+	PLACEHOLDER_OFFSET:     true,
+	PLACEHOLDER_LIMIT:      true,
+	PLACEHOLDER_CURSOR:     true,
+	PLACEHOLDER_KEY:        true,
+	PLACEHOLDER_START_TIME: true,
+	PLACEHOLDER_END_TIME:   true,
+}
+
+type templateSegment struct {
+	literal     string
+	placeholder string
+	inQuery     bool
+}
+
+// url template with named placeholders (e.g. https://api/x?offset={offset}&limit={limit}),
+// falls back to single integer placeholder (%d) when no named placeholder found
+type urlTemplate struct {
+	raw      string
+	legacy   bool
+	verbatim bool
+	json     bool
+	segments []templateSegment
+}
+
+func newURLTemplate(raw string) *urlTemplate {
+
+	template := parseTemplate(raw)
+
+	if len(template.segments) == 0 {
+		template.legacy = strings.Contains(raw, "%d")
+	}
+
+	return template
+}
+
+// template with named placeholders, substituted values are written as is
+func newVerbatimTemplate(raw string) *urlTemplate {
+
+	template := parseTemplate(raw)
+	template.verbatim = true
+
+	return template
+}
+
+// json request body template with named placeholders, substituted values are escaped as json string content
+func newBodyTemplate(raw string) *urlTemplate {
+
+	template := newVerbatimTemplate(raw)
+	template.json = true
+
+	return template
+}
+
+func parseTemplate(raw string) *urlTemplate {
+
+	template := &urlTemplate{raw: raw}
+
+	matches := placeholderPattern.FindAllStringSubmatchIndex(raw, -1)
+
+	if len(matches) == 0 {
+		return template
+	}
+
+	last := 0
+	queryStart := strings.Index(raw, "?")
+
+	for _, match := range matches {
+
+		if match[0] > last {
+			template.segments = append(template.segments, templateSegment{literal: raw[last:match[0]]})
+		}
+
+		template.segments = append(template.segments, templateSegment{
+			placeholder: raw[match[2]:match[3]],
+			inQuery:     queryStart >= 0 && match[0] > queryStart,
+		})
+
+		last = match[1]
+	}
+
+	if last < len(raw) {
+		template.segments = append(template.segments, templateSegment{literal: raw[last:]})
+	}
+
+	return template
+}
+
+func (obj *urlTemplate) placeholders() []string {
+
+	var names []string
+
+	for _, segment := range obj.segments {
+		if segment.placeholder != "" {
+			names = append(names, segment.placeholder)
+		}
+	}
+
+	return names
+}
+
+func (obj *urlTemplate) hasPlaceholder(name string) bool {
+
+	for _, placeholder := range obj.placeholders() {
+		if placeholder == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validate every placeholder is known and resulting url is parseable
+func (obj *urlTemplate) validate(known map[string]bool) error {
+
+	for _, placeholder := range obj.placeholders() {
+		if !known[placeholder] {
+			return fmt.Errorf("Unknown URL Placeholder {%s}", placeholder)
+		}
+	}
+
+	if strings.Count(obj.raw, "%d") > 1 && obj.legacy {
+		return errors.New("URL Must Contain Exactly One Integer Placeholder (%d)")
+	}
+
+	if obj.verbatim {
+		return nil
+	}
+
+	values := map[string]string{}
+
+	for placeholder := range known {
+		values[placeholder] = "0"
+	}
+
+	if _, err := url.Parse(obj.render(0, values)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (obj *urlTemplate) render(pointer int, values map[string]string) string {
+
+	// literal replace keep escaped characters of url (e.g. %3D)
+	if obj.legacy {
+		return strings.Replace(obj.raw, "%d", strconv.Itoa(pointer), 1)
+	}
+
+	if len(obj.segments) == 0 {
+		return obj.raw
+	}
+
+	var builder strings.Builder
+
+	for _, segment := range obj.segments {
+
+		if segment.placeholder == "" {
+			builder.WriteString(segment.literal)
+			continue
+		}
+
+		if obj.json {
+			builder.WriteString(jsonEscape(values[segment.placeholder]))
+		} else if obj.verbatim {
+			builder.WriteString(values[segment.placeholder])
+		} else if segment.inQuery {
+			builder.WriteString(url.QueryEscape(values[segment.placeholder]))
+		} else {
+			builder.WriteString(url.PathEscape(values[segment.placeholder]))
+		}
+	}
+
+	return builder.String()
+}
+
+// escape value as content of json string, numbers and plain words are kept as they are
+func jsonEscape(value string) string {
+
+	var buffer bytes.Buffer
+
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(value)
+
+	escaped := bytes.TrimSpace(buffer.Bytes())
+
+	return string(escaped[1 : len(escaped)-1])
+}
+
+func validateParams(params map[string]string) error {
+
+	for key := range params {
+		if reservedPlaceholders[key] {
+			return errors.New("Param " + key + " Overrides Reserved Placeholder")
+		}
+	}
+
+	return nil
+}
+
+// set single query param of url, other params are kept as they are written (e.g. order and encoding of filter=name%3Dx)
+func setQueryParam(location *url.URL, key string, value string) {
+
+	param := url.QueryEscape(key) + "=" + url.QueryEscape(value)
+
+	var params []string
+
+	found := false
+
+	for _, part := range strings.Split(location.RawQuery, "&") {
+
+		if part == "" {
+			continue
+		}
+
+		name, _, _ := strings.Cut(part, "=")
+
+		if unescaped, err := url.QueryUnescape(name); err != nil || unescaped != key {
+			params = append(params, part)
+			continue
+		}
+
+		if !found {
+			params = append(params, param)
+			found = true
+		}
+	}
+
+	if !found {
+		params = append(params, param)
+	}
+
+	location.RawQuery = strings.Join(params, "&")
+}
+
+func (obj *PaginationAggregator) templateValues(pointer int, cursor string) map[string]string {
+
+	values := map[string]string{
+		PLACEHOLDER_PAGE:    strconv.Itoa(pointer),
+		PLACEHOLDER_POINTER: strconv.Itoa(pointer),
+		PLACEHOLDER_OFFSET:  strconv.Itoa((pointer - 1) * obj.limit),
+		PLACEHOLDER_LIMIT:   strconv.Itoa(obj.limit),
+		PLACEHOLDER_CURSOR:  cursor,
+	}
+
+	for key, value := range obj.params {
+		values[key] = value
+	}
+
+	return values
+}
+
+func (obj *PaginationAggregator) buildURL(pointer int, values map[string]string) string {
+	return obj.template.render(pointer, values)
+}
+
+func (obj *PaginationAggregator) buildBody(pointer int, values map[string]string) io.Reader {
+
+	if obj.body == nil {
+		return nil
+	}
+
+	return strings.NewReader(obj.body.render(pointer, values))
+}
@@ -0,0 +1,84 @@
+package paginationaggregator
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const DEFAULT_XML_TOKEN_QUERY_PARAM = "continuation-token"
+
+// XMLToken follow continuation token of xml listing (e.g. S3 ListObjectsV2 IsTruncated and NextContinuationToken)
+type XMLToken struct {
+
+	// Dot separated element path of next token from root element (e.g. NextContinuationToken)
+	TokenPath string
+
+	// Dot separated element path of truncation flag (e.g. IsTruncated), empty path follow token until it is absent
+	TruncatedPath string
+
+	// Dot separated element path of items (e.g. Contents), used as ItemsPath when it is not configured
+	ItemsPath string
+
+	// Query param to pass token on next request, default continuation-token
+	QueryParam string
+}
+
+func (obj *XMLToken) Apply(req *http.Request, cursor string) error {
+
+	if cursor == "" {
+		return nil
+	}
+
+	setQueryParam(req.URL, obj.queryParam(), cursor)
+
+	return nil
+}
+
+func (obj *XMLToken) Next(interaction *HttpInteraction) (string, bool) {
+
+	tree, err := XMLDecoder.Decode([]byte(interaction.Response.Data))
+
+	if err != nil {
+		interaction.Response.Error = err
+		return "", false
+	}
+
+	if obj.TruncatedPath != "" {
+
+		truncated, _ := lookupPath(tree, obj.TruncatedPath)
+
+		if !strings.EqualFold(fmt.Sprint(truncated), "true") {
+			return "", false
+		}
+	}
+
+	token, ok := lookupPath(tree, obj.TokenPath)
+
+	if !ok {
+		return "", false
+	}
+
+	cursor, ok := token.(string)
+
+	return cursor, ok && cursor != ""
+}
+
+func (obj *XMLToken) queryParam() string {
+
+	if obj.QueryParam == "" {
+		return DEFAULT_XML_TOKEN_QUERY_PARAM
+	}
+
+	return obj.QueryParam
+}
+
+func (obj *XMLToken) validate() error {
+
+	if obj.TokenPath == "" {
+		return errors.New("No XML Token Path Found")
+	}
+
+	return nil
+}